@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/fusionn-subs/internal/config"
+	"github.com/fusionn-subs/internal/service/modelselection"
+	"github.com/fusionn-subs/internal/service/translator"
+	"github.com/fusionn-subs/pkg/logger"
+)
+
+// modelAutoSelector runs the OpenRouter model selector while
+// openrouter.auto_select_model is enabled and feeds its picks to the
+// OpenRouter translator.
+type modelAutoSelector struct {
+	translator translator.ModelUpdater
+	store      modelselection.StateStore
+
+	mu      sync.Mutex // Serializes apply/stop, held during the startup evaluation
+	running modelselection.Config
+
+	selMu    sync.RWMutex // Guards selector only, so job feedback never waits on an evaluation
+	selector *modelselection.Selector
+}
+
+// newModelAutoSelector returns nil if no OpenRouter translator is configured.
+// The selection is persisted in store so restarts reuse it. OpenRouter job
+// failures are fed back so failing models get demoted; outputs the worker
+// validates arrive through reportValidated.
+func newModelAutoSelector(trans translator.Translator, store modelselection.StateStore) *modelAutoSelector {
+	updater, ok := translator.FindModelUpdater(trans, "openrouter")
+	if !ok {
+		return nil
+	}
+	a := &modelAutoSelector{translator: updater, store: store}
+	translator.Observe(trans, "openrouter", func(model string, err error) {
+		if s := a.current(); s != nil {
+			s.ReportOutcome(model, err)
+		}
+	})
+	return a
+}
+
+// reportValidated feeds back the output validation of an OpenRouter
+// translation: rejected output counts as a failure of the model that wrote it.
+func (a *modelAutoSelector) reportValidated(provider, model string, err error) {
+	if provider != "openrouter" {
+		return
+	}
+	if s := a.current(); s != nil {
+		s.ReportOutcome(model, err)
+	}
+}
+
+func (a *modelAutoSelector) current() *modelselection.Selector {
+	a.selMu.RLock()
+	defer a.selMu.RUnlock()
+	return a.selector
+}
+
+func (a *modelAutoSelector) setCurrent(s *modelselection.Selector) {
+	a.selMu.Lock()
+	a.selector = s
+	a.selMu.Unlock()
+}
+
+// selectorConfig builds the selector config; ok is false when auto-selection
+// is disabled.
+func selectorConfig(cfg *config.Config) (modelselection.Config, bool) {
+	if !cfg.OpenRouter.AutoSelectModel {
+		return modelselection.Config{}, false
+	}
+
+	return modelselection.Config{
+		OpenRouterAPIKey: cfg.OpenRouter.APIKey,
+		Evaluator:        evaluatorLLMConfig(cfg),
+		DefaultModel:     cfg.OpenRouter.Model,
+		ScheduleHour:     cfg.OpenRouter.Evaluator.ScheduleHour,
+		Benchmark:        cfg.OpenRouter.Evaluator.Mode == config.EvaluatorModeBenchmark,
+		SourceLanguage:   cfg.Translator.SourceLanguage,
+		TargetLanguage:   evaluationLanguage(cfg),
+		Filter:           candidateFilter(cfg),
+		MaxCandidates:    cfg.OpenRouter.Evaluator.MaxCandidates,
+		ScoreboardPath:   cfg.OpenRouter.Evaluator.ScoreboardPath,
+		DemoteAfter:      cfg.OpenRouter.Evaluator.DemoteAfter,
+	}, true
+}
+
+// evaluatorLLMConfig resolves the evaluator model's connection settings,
+// reusing the credentials of the chosen provider's own config section.
+func evaluatorLLMConfig(cfg *config.Config) modelselection.EvaluatorLLMConfig {
+	ev := cfg.OpenRouter.Evaluator
+	llm := modelselection.EvaluatorLLMConfig{Provider: ev.Provider, Model: ev.Model}
+
+	switch ev.Provider {
+	case modelselection.ProviderOpenRouter:
+		llm.APIKey = ev.APIKey
+		if llm.APIKey == "" {
+			llm.APIKey = cfg.OpenRouter.APIKey
+		}
+	case modelselection.ProviderLocalLLM:
+		llm.APIKey = cfg.LocalLLM.APIKey
+		llm.BaseURL = cfg.LocalLLM.BaseURL
+		llm.Endpoint = cfg.LocalLLM.Endpoint
+		if llm.Model == "" {
+			llm.Model = cfg.LocalLLM.Model
+		}
+	default:
+		llm.APIKey = ev.GeminiAPIKey
+		if llm.APIKey == "" {
+			llm.APIKey = cfg.Gemini.APIKey
+		}
+	}
+	return llm
+}
+
+// evaluationLanguage is the target language models are evaluated for: the
+// first default target, which is translator.target_language unless
+// translator.targets is set.
+func evaluationLanguage(cfg *config.Config) string {
+	return cfg.Translator.DefaultTargets()[0].Language
+}
+
+// candidateFilter returns the evaluator filter configured for the evaluation
+// language. Viper lowercases map keys, so the lookup is case-insensitive.
+func candidateFilter(cfg *config.Config) modelselection.CandidateFilter {
+	f := cfg.OpenRouter.Evaluator.CandidateFilters[strings.ToLower(evaluationLanguage(cfg))]
+	return modelselection.CandidateFilter{Include: f.Include, Exclude: f.Exclude}
+}
+
+// apply starts, restarts or stops the selector to match cfg. Starting blocks
+// until the initial evaluation is done.
+func (a *modelAutoSelector) apply(ctx context.Context, cfg *config.Config) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	sc, enabled := selectorConfig(cfg)
+	sc.Store = a.store
+	if enabled && a.current() != nil && reflect.DeepEqual(sc, a.running) {
+		return
+	}
+
+	if old := a.current(); old != nil {
+		old.Stop()
+		a.setCurrent(nil)
+		if !enabled {
+			logger.Infof("🛑 Model auto-selection disabled, using configured model: %s", cfg.OpenRouter.Model)
+			a.translator.UpdateModel(cfg.OpenRouter.Model)
+			return
+		}
+		logger.Infof("🔄 Model selector settings changed, restarting")
+	}
+	if !enabled {
+		return
+	}
+
+	selector, err := modelselection.NewSelector(sc)
+	if err != nil {
+		logger.Errorf("❌ Model selector error: %v (using %s)", err, cfg.OpenRouter.Model)
+		return
+	}
+	selector.OnModelUpdate(func(model string) {
+		if a.current() == selector {
+			a.translator.UpdateModel(model)
+		}
+	})
+	if err := selector.Start(ctx); err != nil {
+		logger.Errorf("❌ Model selector failed to start: %v (using %s)", err, cfg.OpenRouter.Model)
+		return
+	}
+
+	a.setCurrent(selector)
+	a.running = sc
+
+	// The initial pick does not fire OnModelUpdate callbacks
+	a.translator.UpdateModel(selector.GetCurrentModel())
+}
+
+// stop stops the running selector, if any.
+func (a *modelAutoSelector) stop() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if s := a.current(); s != nil {
+		s.Stop()
+		a.setCurrent(nil)
+	}
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/fusionn-subs/internal/client/callback"
 	"github.com/fusionn-subs/internal/config"
+	"github.com/fusionn-subs/internal/service/modelselection"
 	"github.com/fusionn-subs/internal/service/translator"
 	"github.com/fusionn-subs/internal/service/worker"
 	"github.com/fusionn-subs/internal/version"
@@ -101,15 +102,65 @@ func run() error {
 		})
 	}
 
+	// OpenRouter auto model selection (restarted on config reload)
+	selectorStore := modelselection.NewRedisStateStore(redisClient, cfg.Redis.Queue+":model-selection")
+	var onOutcome func(provider, model string, err error)
+	if autoSelect := newModelAutoSelector(translatorSvc, selectorStore); autoSelect != nil {
+		onOutcome = autoSelect.reportValidated
+		autoSelect.apply(ctx, cfg)
+		defer autoSelect.stop()
+		cfgMgr.OnChange(func(old, new *config.Config) {
+			go autoSelect.apply(ctx, new)
+		})
+	} else if cfg.OpenRouter.AutoSelectModel {
+		logger.Warnf("⚠️  openrouter.auto_select_model is enabled but OpenRouter is not a configured provider")
+	}
+
+	// Set default worker pool config if not provided
+	if cfg.Worker.Concurrency <= 0 {
+		cfg.Worker.Concurrency = 1
+	}
+	if cfg.Worker.DrainTimeout == 0 {
+		cfg.Worker.DrainTimeout = config.DefaultWorkerDrainTimeout
+	}
+
+	workerID := cfg.Redis.WorkerID
+	if workerID == "" {
+		workerID, err = os.Hostname()
+		if err != nil {
+			return fmt.Errorf("resolve worker id: %w", err)
+		}
+	}
+
 	workerSvc := worker.New(redisClient, worker.Config{
 		Queue:                 cfg.Redis.Queue,
 		PollTimeout:           config.DefaultWorkerPollTimeout,
 		MaxTranslationRetries: cfg.Translator.MaxTranslationRetries,
+		WorkerID:              workerID,
+		HeartbeatTTL:          config.DefaultWorkerHeartbeatTTL,
+		DeadLetterQueue:       cfg.Redis.DeadLetterQueue,
+		Concurrency:           cfg.Worker.Concurrency,
+		DrainTimeout:          cfg.Worker.DrainTimeout,
+		MaxUntranslatedRatio:  cfg.Translator.MaxUntranslatedRatio,
+		Targets:               cfg.Translator.DefaultTargets(),
+		Memory:                cfg.Translator.Memory,
+		IdempotencyTTL:        cfg.Worker.IdempotencyTTL,
+		Priority:              cfg.Worker.Priority,
+		Bilingual:             cfg.Translator.Bilingual,
+		ASSFont:               cfg.Translator.ASS.Font,
+		OutputFormat:          cfg.Translator.OutputFormat,
+		Cleanup:               cfg.Translator.Cleanup,
+		Providers:             cfg.ActiveProviders(),
+		Encoding:              cfg.Translator.Encoding,
+		OnOutcome:             onOutcome,
 	}, translatorSvc, callbackClient)
 
 	logger.Info("")
 	logger.Info("────────────────────────────────────────────")
-	logger.Infof("✅ Ready! Listening on queue: %s", cfg.Redis.Queue)
+	logger.Infof("✅ Ready! Listening on queue: %s (worker: %s, concurrency: %d)", cfg.Redis.Queue, workerID, cfg.Worker.Concurrency)
+	if cfg.Worker.Priority.Enabled {
+		logger.Infof("🚦 Priority lanes: %s:{high,normal,low}", cfg.Redis.Queue)
+	}
 	logger.Info("────────────────────────────────────────────")
 
 	// Run worker (blocks until context canceled)
@@ -14,7 +14,15 @@ type Payload struct {
 	JobID           string `json:"job_id"`
 	VideoPath       string `json:"video_path"`
 	EngSubtitlePath string `json:"eng_subtitle_path"`
-	ChsSubtitlePath string `json:"chs_subtitle_path"`
+	ChsSubtitlePath string `json:"chs_subtitle_path"` // Output of the first target, kept for older receivers
+
+	// Subtitles maps each target's language code (e.g. "chs", "cht") to its
+	// translated file.
+	Subtitles map[string]string `json:"subtitles"`
+
+	// Bilingual maps target codes to the bilingual file written alongside the
+	// translation, when bilingual output is enabled.
+	Bilingual map[string]string `json:"bilingual,omitempty"`
 }
 
 type Client struct {
@@ -0,0 +1,150 @@
+package gemini
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+
+	"github.com/fusionn-subs/pkg/logger"
+)
+
+const defaultBaseURL = "https://generativelanguage.googleapis.com/v1beta/models"
+
+// Client calls the Gemini generateContent REST API.
+type Client struct {
+	apiKey  string
+	baseURL string
+	client  *resty.Client
+}
+
+// Request is a single-turn generateContent call.
+type Request struct {
+	SystemInstruction string
+	Prompt            string
+	Temperature       float64
+	// ResponseSchema, when set, requests JSON output matching the schema
+	// (OpenAPI subset, e.g. {"type": "OBJECT", ...}).
+	ResponseSchema map[string]any
+}
+
+// APIError is the structured error body returned by the Gemini API.
+type APIError struct {
+	StatusCode int
+	Code       int    `json:"code"`
+	Message    string `json:"message"`
+	Status     string `json:"status"` // e.g. "RESOURCE_EXHAUSTED", "INVALID_ARGUMENT"
+}
+
+func (e *APIError) Error() string {
+	if e.Status != "" {
+		return fmt.Sprintf("API error %d (%s): %s", e.StatusCode, e.Status, e.Message)
+	}
+	return fmt.Sprintf("API error %d: %s", e.StatusCode, e.Message)
+}
+
+// IsResourceExhausted reports whether the model's quota or rate limit is used up.
+func (e *APIError) IsResourceExhausted() bool {
+	return e.Status == "RESOURCE_EXHAUSTED" || e.StatusCode == 429
+}
+
+type response struct {
+	Candidates []struct {
+		Content struct {
+			Parts []struct {
+				Text string `json:"text"`
+			} `json:"parts"`
+		} `json:"content"`
+		FinishReason string `json:"finishReason"`
+	} `json:"candidates"`
+	Error *APIError `json:"error"`
+}
+
+// NewClient creates a Gemini REST client. timeout bounds a single request.
+func NewClient(apiKey string, timeout time.Duration) *Client {
+	return &Client{
+		apiKey:  apiKey,
+		baseURL: defaultBaseURL,
+		client: resty.New().
+			SetTimeout(timeout).
+			SetHeader("Content-Type", "application/json").
+			SetRetryCount(3).                      // Retry up to 3 times for transient failures
+			SetRetryWaitTime(5 * time.Second).     // Initial wait: 5s
+			SetRetryMaxWaitTime(30 * time.Second). // Max wait: 30s (exponential backoff)
+			AddRetryCondition(func(r *resty.Response, err error) bool {
+				// Retry on 429 (rate limit) or 5xx (server errors)
+				return r.StatusCode() == 429 || r.StatusCode() >= 500
+			}).
+			OnAfterResponse(func(c *resty.Client, r *resty.Response) error {
+				if r.Request.Attempt > 1 {
+					logger.Warnf("⚠️  Gemini API retry attempt #%d (status: %d)", r.Request.Attempt-1, r.StatusCode())
+				}
+				return nil
+			}),
+	}
+}
+
+// GenerateContent sends req to model and returns the text of the first candidate.
+// Non-2xx responses are returned as *APIError.
+func (c *Client) GenerateContent(ctx context.Context, model string, req Request) (string, error) {
+	url := fmt.Sprintf("%s/%s:generateContent", c.baseURL, model)
+
+	generationConfig := map[string]any{
+		"temperature": req.Temperature,
+	}
+	if req.ResponseSchema != nil {
+		generationConfig["responseMimeType"] = "application/json"
+		generationConfig["responseSchema"] = req.ResponseSchema
+	}
+
+	body := map[string]any{
+		"contents": []map[string]any{
+			{
+				"parts": []map[string]string{
+					{"text": req.Prompt},
+				},
+			},
+		},
+		"generationConfig": generationConfig,
+	}
+	if req.SystemInstruction != "" {
+		body["system_instruction"] = map[string]any{
+			"parts": []map[string]string{
+				{"text": req.SystemInstruction},
+			},
+		}
+	}
+
+	var result response
+	resp, err := c.client.R().
+		SetContext(ctx).
+		SetQueryParam("key", c.apiKey).
+		SetBody(body).
+		SetResult(&result).
+		SetError(&result).
+		Post(url)
+	if err != nil {
+		return "", fmt.Errorf("http request: %w", err)
+	}
+
+	if !resp.IsSuccess() {
+		apiErr := &APIError{StatusCode: resp.StatusCode(), Message: resp.String()}
+		if result.Error != nil {
+			apiErr = result.Error
+			apiErr.StatusCode = resp.StatusCode()
+		}
+		return "", apiErr
+	}
+
+	if len(result.Candidates) == 0 || len(result.Candidates[0].Content.Parts) == 0 {
+		return "", fmt.Errorf("no response from Gemini")
+	}
+
+	candidate := result.Candidates[0]
+	if candidate.FinishReason == "MAX_TOKENS" {
+		return "", fmt.Errorf("response truncated (finishReason=MAX_TOKENS), reduce max_batch_size")
+	}
+
+	return candidate.Content.Parts[0].Text, nil
+}
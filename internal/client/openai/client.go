@@ -0,0 +1,128 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+
+	"github.com/fusionn-subs/pkg/logger"
+)
+
+// Client calls an OpenAI-compatible chat completions endpoint
+// (OpenRouter, LM Studio, Ollama, vLLM, ...).
+type Client struct {
+	url    string
+	client *resty.Client
+}
+
+// Message is a single chat message.
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// ChatRequest is the request body for /chat/completions.
+type ChatRequest struct {
+	Model       string    `json:"model,omitempty"`
+	Messages    []Message `json:"messages"`
+	Temperature float64   `json:"temperature"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message      Message `json:"message"`
+		FinishReason string  `json:"finish_reason"`
+	} `json:"choices"`
+}
+
+type errorResponse struct {
+	Error struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+		Code    any    `json:"code"`
+	} `json:"error"`
+}
+
+// APIError is returned for non-2xx responses so callers can map status codes.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error %d: %s", e.StatusCode, e.Message)
+}
+
+// NewClient creates a chat completions client for baseURL + endpoint.
+// apiKey may be empty for local servers.
+func NewClient(baseURL, endpoint, apiKey string, timeout time.Duration) *Client {
+	httpClient := resty.New().
+		SetTimeout(timeout).
+		SetHeader("Content-Type", "application/json").
+		SetHeader("HTTP-Referer", "https://github.com/fusionn-subs"). // For OpenRouter analytics
+		SetHeader("X-Title", "fusionn-subs").                         // App identifier
+		SetRetryCount(3).                                             // Retry up to 3 times for transient failures
+		SetRetryWaitTime(5 * time.Second).                            // Initial wait: 5s
+		SetRetryMaxWaitTime(30 * time.Second).                        // Max wait: 30s (exponential backoff)
+		AddRetryCondition(func(r *resty.Response, err error) bool {
+			// Retry on 429 (rate limit) or 5xx (server errors)
+			return r.StatusCode() == 429 || r.StatusCode() >= 500
+		}).
+		OnAfterResponse(func(c *resty.Client, r *resty.Response) error {
+			if r.Request.Attempt > 1 {
+				logger.Warnf("⚠️  Chat API retry attempt #%d (status: %d)", r.Request.Attempt-1, r.StatusCode())
+			}
+			return nil
+		})
+
+	if apiKey != "" {
+		httpClient.SetHeader("Authorization", "Bearer "+apiKey)
+	}
+
+	return &Client{
+		url:    strings.TrimRight(baseURL, "/") + "/" + strings.TrimLeft(endpoint, "/"),
+		client: httpClient,
+	}
+}
+
+// Complete sends a chat request and returns the first choice's content.
+// A reply cut off by the token limit is reported as an error.
+func (c *Client) Complete(ctx context.Context, req ChatRequest) (string, error) {
+	var result chatResponse
+	var apiErr errorResponse
+
+	resp, err := c.client.R().
+		SetContext(ctx).
+		SetBody(req).
+		SetResult(&result).
+		SetError(&apiErr).
+		Post(c.url)
+	if err != nil {
+		return "", fmt.Errorf("http request: %w", err)
+	}
+
+	if !resp.IsSuccess() {
+		msg := apiErr.Error.Message
+		if msg == "" {
+			msg = resp.String()
+			if len(msg) > 200 {
+				msg = msg[:200] + "..."
+			}
+		}
+		return "", &APIError{StatusCode: resp.StatusCode(), Message: msg}
+	}
+
+	if len(result.Choices) == 0 {
+		return "", fmt.Errorf("no choices in response")
+	}
+
+	choice := result.Choices[0]
+	if choice.FinishReason == "length" {
+		return "", fmt.Errorf("response truncated (finish_reason=length), reduce max_batch_size")
+	}
+
+	return choice.Message.Content, nil
+}
@@ -10,7 +10,8 @@ import (
 	"github.com/fusionn-subs/pkg/logger"
 )
 
-const defaultBaseURL = "https://openrouter.ai/api/v1"
+// DefaultBaseURL is the OpenRouter API root, also usable as an OpenAI-compatible base URL.
+const DefaultBaseURL = "https://openrouter.ai/api/v1"
 
 // Client interacts with OpenRouter API.
 type Client struct {
@@ -34,7 +35,7 @@ type Model struct {
 // NewClient creates an OpenRouter API client.
 func NewClient(apiKey string) *Client {
 	return &Client{
-		baseURL: defaultBaseURL,
+		baseURL: DefaultBaseURL,
 		apiKey:  apiKey,
 		client: resty.New().
 			SetTimeout(60*time.Second). // Generous timeout for large model list (~600+ models)
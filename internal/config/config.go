@@ -4,12 +4,15 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/spf13/viper"
 
+	"github.com/fusionn-subs/internal/subtitle"
+	"github.com/fusionn-subs/internal/types"
 	"github.com/fusionn-subs/internal/util"
 	"github.com/fusionn-subs/pkg/logger"
 )
@@ -21,6 +24,49 @@ const (
 	DefaultGeminiTimeout      = 30 * time.Minute
 	DefaultLocalLLMTimeout    = 30 * time.Minute
 	DefaultWorkerPollTimeout  = 5 * time.Second
+	DefaultWorkerHeartbeatTTL = 30 * time.Second
+	DefaultWorkerDrainTimeout = 2 * time.Minute
+	DefaultMemoryKeyPrefix    = "fusionn-subs:tm"
+)
+
+// Model evaluation modes for openrouter.evaluator.mode.
+const (
+	EvaluatorModeMetadata  = "metadata"
+	EvaluatorModeBenchmark = "benchmark"
+)
+
+// Bilingual output modes, formats and orders for translator.bilingual.
+const (
+	BilingualOff       = "off"
+	BilingualAlongside = "alongside" // Written next to the translation-only file
+	BilingualOnly      = "only"      // Written instead of the translation-only file
+
+	BilingualFormatSRT = "srt"
+	BilingualFormatASS = "ass"
+	BilingualFormatVTT = "vtt"
+
+	BilingualTranslationFirst = "translation_first"
+	BilingualSourceFirst      = "source_first"
+)
+
+// Output formats for translator.output_format. Empty keeps the source format.
+const (
+	OutputFormatSRT = "srt"
+	OutputFormatVTT = "vtt"
+	OutputFormatASS = "ass"
+)
+
+// Byte order mark policies for translator.encoding.bom.
+const (
+	BOMKeep   = "keep" // Write a BOM if the source had one
+	BOMAdd    = "add"
+	BOMRemove = "remove"
+)
+
+// Translation engines. "script" runs llm-subtrans; "native" calls the API directly.
+const (
+	EngineScript = "script"
+	EngineNative = "native"
 )
 
 type Config struct {
@@ -30,11 +76,14 @@ type Config struct {
 	OpenRouter OpenRouterConfig `mapstructure:"openrouter"`
 	LocalLLM   LocalLLMConfig   `mapstructure:"local_llm"`
 	Translator TranslatorConfig `mapstructure:"translator"`
+	Worker     WorkerConfig     `mapstructure:"worker"`
 }
 
 type RedisConfig struct {
-	URL   string `mapstructure:"url"`
-	Queue string `mapstructure:"queue"`
+	URL             string `mapstructure:"url"`
+	Queue           string `mapstructure:"queue"`
+	WorkerID        string `mapstructure:"worker_id"`         // Defaults to hostname; must be unique per instance
+	DeadLetterQueue string `mapstructure:"dead_letter_queue"` // Defaults to "<queue>:dead"
 }
 
 type CallbackConfig struct {
@@ -55,6 +104,8 @@ type GeminiConfig struct {
 	Instruction    string            `mapstructure:"instruction"`
 	PrimaryModel   GeminiModelConfig `mapstructure:"primary_model"`
 	SecondaryModel GeminiModelConfig `mapstructure:"secondary_model"`
+	Concurrency    int               `mapstructure:"concurrency"` // Max simultaneous jobs (default: 1)
+	Engine         string            `mapstructure:"engine"`      // "script" (default, no checkpoints) or "native"
 }
 
 type OpenRouterConfig struct {
@@ -65,6 +116,8 @@ type OpenRouterConfig struct {
 	RateLimit       int             `mapstructure:"rate_limit"`
 	AutoSelectModel bool            `mapstructure:"auto_select_model"`
 	Evaluator       EvaluatorConfig `mapstructure:"evaluator"`
+	Concurrency     int             `mapstructure:"concurrency"` // Max simultaneous jobs (default: 1)
+	Engine          string          `mapstructure:"engine"`      // "script" (default, no checkpoints) or "native"
 }
 
 type LocalLLMConfig struct {
@@ -76,26 +129,155 @@ type LocalLLMConfig struct {
 	RateLimit    int           `mapstructure:"rate_limit"`
 	MaxBatchSize int           `mapstructure:"max_batch_size"`
 	Timeout      time.Duration `mapstructure:"timeout"`
+	Concurrency  int           `mapstructure:"concurrency"` // Max simultaneous jobs (default: 1)
+	Engine       string        `mapstructure:"engine"`      // "script" (default, no checkpoints) or "native"
 }
 
 type EvaluatorConfig struct {
-	Provider     string `mapstructure:"provider"`
-	GeminiAPIKey string `mapstructure:"gemini_api_key"`
-	Model        string `mapstructure:"model"`
-	ScheduleHour int    `mapstructure:"schedule_hour"` // Hour of day (0-23) for daily evaluation
+	Provider       string `mapstructure:"provider"` // "gemini", "openrouter" or "local_llm"
+	GeminiAPIKey   string `mapstructure:"gemini_api_key"`
+	APIKey         string `mapstructure:"api_key"` // openrouter provider only; defaults to openrouter.api_key
+	Model          string `mapstructure:"model"`
+	ScheduleHour   int    `mapstructure:"schedule_hour"`   // Hour of day (0-23) for daily evaluation
+	Mode           string `mapstructure:"mode"`            // "metadata" (default) or "benchmark"
+	MaxCandidates  int    `mapstructure:"max_candidates"`  // Models benchmarked per run (default: 6)
+	ScoreboardPath string `mapstructure:"scoreboard_path"` // Where the benchmark scoreboard is written
+	DemoteAfter    int    `mapstructure:"demote_after"`    // Consecutive job failures before switching models (default: 3)
+
+	// Per target language (e.g. "japanese"), substrings of model IDs to keep or drop
+	CandidateFilters map[string]CandidateFilterConfig `mapstructure:"candidate_filters"`
+}
+
+type CandidateFilterConfig struct {
+	Include []string `mapstructure:"include"`
+	Exclude []string `mapstructure:"exclude"`
 }
 
 type TranslatorConfig struct {
-	Providers             []string `mapstructure:"providers"`
-	TargetLanguage        string   `mapstructure:"target_language"`
-	OutputSuffix          string   `mapstructure:"output_suffix"`
-	MaxTranslationRetries int      `mapstructure:"max_translation_retries"`
+	Providers             []string        `mapstructure:"providers"`
+	SourceLanguage        string          `mapstructure:"source_language"` // Language of the input subtitles, used by model evaluation (default: English)
+	TargetLanguage        string          `mapstructure:"target_language"`
+	OutputSuffix          string          `mapstructure:"output_suffix"`
+	Targets               []types.Target  `mapstructure:"targets"` // Default targets (code, language, suffix); replaces target_language/output_suffix when set
+	MaxTranslationRetries int             `mapstructure:"max_translation_retries"`
+	MaxUntranslatedRatio  float64         `mapstructure:"max_untranslated_ratio"` // Reject output above this share of untranslated cues
+	OutputFormat          string          `mapstructure:"output_format"`          // "srt", "vtt" or "ass"; empty keeps the source format
+	Memory                MemoryConfig    `mapstructure:"memory"`
+	Bilingual             BilingualConfig `mapstructure:"bilingual"`
+	ASS                   ASSConfig       `mapstructure:"ass"`
+	Cleanup               CleanupConfig   `mapstructure:"cleanup"`
+	Encoding              EncodingConfig  `mapstructure:"encoding"`
+}
+
+// DefaultTargets returns the languages a job is translated into unless it
+// lists its own: translator.targets, or the single target_language/output_suffix
+// pair when that is empty.
+func (c TranslatorConfig) DefaultTargets() []types.Target {
+	if len(c.Targets) > 0 {
+		return c.Targets
+	}
+	return []types.Target{{Code: c.OutputSuffix, Language: c.TargetLanguage}}
+}
+
+// BilingualConfig controls the combined output in which every cue shows the
+// source line and its translation. SRT, ASS and WebVTT sources only.
+type BilingualConfig struct {
+	Mode             string                  `mapstructure:"mode"`   // "off" (default), "alongside" or "only"
+	Format           string                  `mapstructure:"format"` // "srt" (default), "ass" or "vtt"
+	Order            string                  `mapstructure:"order"`  // "translation_first" (default) or "source_first"
+	Suffix           string                  `mapstructure:"suffix"` // Added to the file name in alongside mode (default: "bi")
+	SourceStyle      subtitle.BilingualStyle `mapstructure:"source_style"`
+	TranslationStyle subtitle.BilingualStyle `mapstructure:"translation_style"`
 }
 
-var validProviders = map[string]bool{
-	"gemini":     true,
-	"openrouter": true,
-	"local_llm":  true,
+// Enabled reports whether bilingual output is written.
+func (c BilingualConfig) Enabled() bool {
+	return c.Mode != "" && c.Mode != BilingualOff
+}
+
+// ASSConfig controls ASS/SSA output.
+type ASSConfig struct {
+	// Font replaces the font of the styles used by translated dialogue, e.g.
+	// "Noto Sans CJK SC"; styles used only by karaoke or drawing events keep
+	// theirs. Empty keeps the source fonts. Targets may set their own.
+	Font string `mapstructure:"font"`
+}
+
+// CleanupConfig controls the pre-processing of source subtitles before they
+// are translated. The rules set here apply to every provider without an entry
+// in Providers; an entry replaces them entirely for jobs that provider runs
+// first.
+type CleanupConfig struct {
+	CleanupRulesConfig `mapstructure:",squash"`
+	Providers          map[string]CleanupRulesConfig `mapstructure:"providers"`
+}
+
+// CleanupRulesConfig toggles the cleanup rules; all are off by default.
+type CleanupRulesConfig struct {
+	StripHearingImpaired bool     `mapstructure:"strip_hearing_impaired"` // [DOOR CREAKS], (sighs), "JOHN:" speaker labels
+	StripMusic           bool     `mapstructure:"strip_music"`            // Lyrics and music lines marked with ♪ or #
+	RemoveCredits        bool     `mapstructure:"remove_credits"`         // "Subtitles by ..." and ad cues
+	CreditPatterns       []string `mapstructure:"credit_patterns"`        // Extra case-insensitive regexes for remove_credits
+	MergeSentences       bool     `mapstructure:"merge_sentences"`        // Join sentences split across cues (not for ASS)
+	NormalizeWhitespace  bool     `mapstructure:"normalize_whitespace"`   // Collapse spaces, drop invisible characters
+}
+
+// Rules returns the subtitle cleanup rules.
+func (c CleanupRulesConfig) Rules() subtitle.CleanupRules {
+	return subtitle.CleanupRules(c)
+}
+
+// For returns the rules applied to jobs that provider runs first.
+func (c CleanupConfig) For(provider string) subtitle.CleanupRules {
+	if rules, ok := c.Providers[provider]; ok {
+		return rules.Rules()
+	}
+	return c.Rules()
+}
+
+// EncodingConfig controls the encoding of delivered subtitles. Sources are
+// always transcoded to UTF-8 before translation, whatever their encoding.
+type EncodingConfig struct {
+	Output string `mapstructure:"output"` // "utf-8" (default), "utf-16le" or "utf-16be"
+	BOM    string `mapstructure:"bom"`    // "keep" (default), "add" or "remove"
+}
+
+// For returns the encoding of the output of a source encoded as source.
+func (c EncodingConfig) For(source subtitle.Encoding) subtitle.Encoding {
+	enc := subtitle.Encoding{Charset: c.Output, BOM: source.BOM}
+	if enc.Charset == "" {
+		enc.Charset = subtitle.CharsetUTF8
+	}
+	switch c.BOM {
+	case BOMAdd:
+		enc.BOM = true
+	case BOMRemove:
+		enc.BOM = false
+	}
+	return enc
+}
+
+// MemoryConfig controls the translation memory: previously translated lines
+// are reused instead of being sent to a provider again.
+type MemoryConfig struct {
+	Enabled   bool          `mapstructure:"enabled"`
+	KeyPrefix string        `mapstructure:"key_prefix"` // Redis key prefix (default: "fusionn-subs:tm")
+	TTL       time.Duration `mapstructure:"ttl"`        // Expiry of each memory hash, refreshed on write (0 = never)
+}
+
+type WorkerConfig struct {
+	Concurrency    int            `mapstructure:"concurrency"`     // Consumer goroutines (default: 1)
+	DrainTimeout   time.Duration  `mapstructure:"drain_timeout"`   // Grace period for in-flight jobs on shutdown
+	IdempotencyTTL time.Duration  `mapstructure:"idempotency_ttl"` // How long finished jobs are remembered (default: 168h)
+	Priority       PriorityConfig `mapstructure:"priority"`
+}
+
+// PriorityConfig sorts queued jobs into high/normal/low lanes
+// ("<queue>:high" etc.) that are consumed highest first.
+type PriorityConfig struct {
+	Enabled         bool              `mapstructure:"enabled"`
+	MediaTypes      map[string]string `mapstructure:"media_types"`      // Priority of jobs without one, by media_type (default: movie=high, episode=normal)
+	StarvationLimit int               `mapstructure:"starvation_limit"` // Every Nth job comes from the lowest lane with work (default: 5)
 }
 
 // ChangeCallback is called when config changes. Receives old and new config.
@@ -266,17 +448,111 @@ func (c *Config) validateOpenRouterAutoSelectModel() error {
 	if c.OpenRouter.Evaluator.Provider == "" {
 		return fmt.Errorf("openrouter.evaluator.provider is required when auto_select_model is enabled")
 	}
-	if c.OpenRouter.Evaluator.Provider != "gemini" {
-		return fmt.Errorf("only 'gemini' is supported as evaluator.provider")
-	}
-	if c.OpenRouter.Evaluator.GeminiAPIKey == "" && c.Gemini.APIKey == "" {
-		return fmt.Errorf("either openrouter.evaluator.gemini_api_key or gemini.api_key is required when auto_select_model is enabled")
+	switch c.OpenRouter.Evaluator.Provider {
+	case "gemini":
+		if c.OpenRouter.Evaluator.GeminiAPIKey == "" && c.Gemini.APIKey == "" {
+			return fmt.Errorf("either openrouter.evaluator.gemini_api_key or gemini.api_key is required when auto_select_model is enabled")
+		}
+		if c.OpenRouter.Evaluator.Model == "" {
+			c.OpenRouter.Evaluator.Model = "gemini-3-flash"
+		}
+	case "openrouter":
+		if c.OpenRouter.Evaluator.Model == "" {
+			return fmt.Errorf("openrouter.evaluator.model is required for the openrouter evaluator (e.g. a paid model as judge)")
+		}
+	case "local_llm":
+		if c.LocalLLM.BaseURL == "" {
+			return fmt.Errorf("local_llm.base_url is required for the local_llm evaluator")
+		}
+		if c.OpenRouter.Evaluator.Model == "" && c.LocalLLM.Model == "" {
+			return fmt.Errorf("openrouter.evaluator.model or local_llm.model is required for the local_llm evaluator")
+		}
+	default:
+		return fmt.Errorf("openrouter.evaluator.provider must be 'gemini', 'openrouter' or 'local_llm'")
 	}
 	if c.OpenRouter.Evaluator.ScheduleHour < 0 || c.OpenRouter.Evaluator.ScheduleHour > 23 {
 		c.OpenRouter.Evaluator.ScheduleHour = 3
 	}
-	if c.OpenRouter.Evaluator.Model == "" {
-		c.OpenRouter.Evaluator.Model = "gemini-3-flash"
+	switch c.OpenRouter.Evaluator.Mode {
+	case "":
+		c.OpenRouter.Evaluator.Mode = EvaluatorModeMetadata
+	case EvaluatorModeMetadata, EvaluatorModeBenchmark:
+	default:
+		return fmt.Errorf("openrouter.evaluator.mode must be %q or %q", EvaluatorModeMetadata, EvaluatorModeBenchmark)
+	}
+	return nil
+}
+
+func validateEngine(field, engine string) error {
+	switch engine {
+	case "", EngineScript, EngineNative:
+		return nil
+	}
+	return fmt.Errorf("%s: unknown engine %q (use %q or %q)", field, engine, EngineScript, EngineNative)
+}
+
+var styleColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+func (c *Config) validateBilingual() error {
+	b := c.Translator.Bilingual
+	switch b.Mode {
+	case "", BilingualOff, BilingualAlongside, BilingualOnly:
+	default:
+		return fmt.Errorf("translator.bilingual.mode must be %q, %q or %q", BilingualOff, BilingualAlongside, BilingualOnly)
+	}
+	switch b.Format {
+	case "", BilingualFormatSRT, BilingualFormatASS, BilingualFormatVTT:
+	default:
+		return fmt.Errorf("translator.bilingual.format must be %q, %q or %q", BilingualFormatSRT, BilingualFormatASS, BilingualFormatVTT)
+	}
+	switch b.Order {
+	case "", BilingualTranslationFirst, BilingualSourceFirst:
+	default:
+		return fmt.Errorf("translator.bilingual.order must be %q or %q", BilingualTranslationFirst, BilingualSourceFirst)
+	}
+	for field, style := range map[string]subtitle.BilingualStyle{"source_style": b.SourceStyle, "translation_style": b.TranslationStyle} {
+		if style.Color != "" && !styleColorPattern.MatchString(style.Color) {
+			return fmt.Errorf("translator.bilingual.%s.color must be #RRGGBB, got %q", field, style.Color)
+		}
+	}
+	return nil
+}
+
+func (c *Config) validateCleanup() error {
+	cl := c.Translator.Cleanup
+	if err := validateCreditPatterns("translator.cleanup", cl.CreditPatterns); err != nil {
+		return err
+	}
+	for provider, rules := range cl.Providers {
+		if !types.IsProvider(provider) {
+			return fmt.Errorf("translator.cleanup.providers: unknown provider %q", provider)
+		}
+		if err := validateCreditPatterns("translator.cleanup.providers."+provider, rules.CreditPatterns); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateCreditPatterns(field string, patterns []string) error {
+	for i, p := range patterns {
+		if _, err := regexp.Compile("(?i)" + p); err != nil {
+			return fmt.Errorf("%s.credit_patterns[%d]: %w", field, i, err)
+		}
+	}
+	return nil
+}
+
+// ActiveProviders returns the providers jobs are translated with, in
+// fallback order: translator.providers, or the single legacy provider.
+func (c *Config) ActiveProviders() []string {
+	switch {
+	case len(c.Translator.Providers) > 0:
+		return c.Translator.Providers
+	case c.Gemini.APIKey != "":
+		return []string{"gemini"}
+	case c.OpenRouter.APIKey != "":
+		return []string{"openrouter"}
 	}
 	return nil
 }
@@ -292,6 +568,45 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("callback.url is required")
 	}
 
+	if err := validateEngine("gemini.engine", c.Gemini.Engine); err != nil {
+		return err
+	}
+	if err := validateEngine("openrouter.engine", c.OpenRouter.Engine); err != nil {
+		return err
+	}
+	if err := validateEngine("local_llm.engine", c.LocalLLM.Engine); err != nil {
+		return err
+	}
+	if err := types.ValidateTargets(c.Translator.Targets); err != nil {
+		return fmt.Errorf("translator.%w", err)
+	}
+	if err := c.validateBilingual(); err != nil {
+		return err
+	}
+	switch c.Translator.OutputFormat {
+	case "", OutputFormatSRT, OutputFormatVTT, OutputFormatASS:
+	default:
+		return fmt.Errorf("translator.output_format must be %q, %q or %q", OutputFormatSRT, OutputFormatVTT, OutputFormatASS)
+	}
+	if err := c.validateCleanup(); err != nil {
+		return err
+	}
+	switch c.Translator.Encoding.Output {
+	case "", subtitle.CharsetUTF8, subtitle.CharsetUTF16LE, subtitle.CharsetUTF16BE:
+	default:
+		return fmt.Errorf("translator.encoding.output must be %q, %q or %q", subtitle.CharsetUTF8, subtitle.CharsetUTF16LE, subtitle.CharsetUTF16BE)
+	}
+	switch c.Translator.Encoding.BOM {
+	case "", BOMKeep, BOMAdd, BOMRemove:
+	default:
+		return fmt.Errorf("translator.encoding.bom must be %q, %q or %q", BOMKeep, BOMAdd, BOMRemove)
+	}
+	for mediaType, p := range c.Worker.Priority.MediaTypes {
+		if !types.IsPriority(p) {
+			return fmt.Errorf("worker.priority.media_types.%s: unknown priority %q", mediaType, p)
+		}
+	}
+
 	if len(c.Translator.Providers) > 0 {
 		trimmed := make([]string, len(c.Translator.Providers))
 		for i, p := range c.Translator.Providers {
@@ -302,7 +617,7 @@ func (c *Config) Validate() error {
 			if p == "" {
 				return fmt.Errorf("translator.providers[%d] is empty", i)
 			}
-			if !validProviders[p] {
+			if !types.IsProvider(p) {
 				return fmt.Errorf("translator.providers: unknown provider %q", p)
 			}
 			if seen[p] {
@@ -441,37 +756,73 @@ func Load(path string) (*Config, error) {
 // SafeLogValues returns config values safe for logging (masks secrets).
 func (c *Config) SafeLogValues() map[string]any {
 	return map[string]any{
-		"redis.url":                             c.Redis.URL,
-		"redis.queue":                           c.Redis.Queue,
-		"callback.url":                          c.Callback.URL,
-		"gemini.api_key":                        util.MaskSecret(c.Gemini.APIKey),
-		"gemini.instruction":                    c.Gemini.Instruction,
-		"gemini.primary_model.name":             c.Gemini.PrimaryModel.Name,
-		"gemini.primary_model.rate_limit":       c.Gemini.PrimaryModel.RateLimit,
-		"gemini.primary_model.max_batch_size":   c.Gemini.PrimaryModel.MaxBatchSize,
-		"gemini.secondary_model.name":           c.Gemini.SecondaryModel.Name,
-		"gemini.secondary_model.rate_limit":     c.Gemini.SecondaryModel.RateLimit,
-		"gemini.secondary_model.max_batch_size": c.Gemini.SecondaryModel.MaxBatchSize,
-		"openrouter.api_key":                    util.MaskSecret(c.OpenRouter.APIKey),
-		"openrouter.model":                      c.OpenRouter.Model,
-		"openrouter.instruction":                c.OpenRouter.Instruction,
-		"openrouter.max_batch_size":             c.OpenRouter.MaxBatchSize,
-		"openrouter.rate_limit":                 c.OpenRouter.RateLimit,
-		"openrouter.auto_select_model":          c.OpenRouter.AutoSelectModel,
-		"openrouter.evaluator.provider":         c.OpenRouter.Evaluator.Provider,
-		"openrouter.evaluator.gemini_api_key":   util.MaskSecret(c.OpenRouter.Evaluator.GeminiAPIKey),
-		"openrouter.evaluator.model":            c.OpenRouter.Evaluator.Model,
-		"openrouter.evaluator.schedule_hour":    c.OpenRouter.Evaluator.ScheduleHour,
-		"translator.providers":                  c.Translator.Providers,
-		"translator.target_lang":                c.Translator.TargetLanguage,
-		"translator.suffix":                     c.Translator.OutputSuffix,
-		"local_llm.base_url":                    c.LocalLLM.BaseURL,
-		"local_llm.api_key":                     util.MaskSecret(c.LocalLLM.APIKey),
-		"local_llm.model":                       c.LocalLLM.Model,
-		"local_llm.endpoint":                    c.LocalLLM.Endpoint,
-		"local_llm.instruction":                 c.LocalLLM.Instruction,
-		"local_llm.rate_limit":                  c.LocalLLM.RateLimit,
-		"local_llm.max_batch_size":              c.LocalLLM.MaxBatchSize,
-		"local_llm.timeout":                     c.LocalLLM.Timeout.String(),
+		"redis.url":                              c.Redis.URL,
+		"redis.queue":                            c.Redis.Queue,
+		"redis.worker_id":                        c.Redis.WorkerID,
+		"redis.dead_letter_queue":                c.Redis.DeadLetterQueue,
+		"callback.url":                           c.Callback.URL,
+		"gemini.api_key":                         util.MaskSecret(c.Gemini.APIKey),
+		"gemini.instruction":                     c.Gemini.Instruction,
+		"gemini.primary_model.name":              c.Gemini.PrimaryModel.Name,
+		"gemini.primary_model.rate_limit":        c.Gemini.PrimaryModel.RateLimit,
+		"gemini.primary_model.max_batch_size":    c.Gemini.PrimaryModel.MaxBatchSize,
+		"gemini.secondary_model.name":            c.Gemini.SecondaryModel.Name,
+		"gemini.secondary_model.rate_limit":      c.Gemini.SecondaryModel.RateLimit,
+		"gemini.secondary_model.max_batch_size":  c.Gemini.SecondaryModel.MaxBatchSize,
+		"gemini.concurrency":                     c.Gemini.Concurrency,
+		"gemini.engine":                          c.Gemini.Engine,
+		"openrouter.api_key":                     util.MaskSecret(c.OpenRouter.APIKey),
+		"openrouter.model":                       c.OpenRouter.Model,
+		"openrouter.instruction":                 c.OpenRouter.Instruction,
+		"openrouter.max_batch_size":              c.OpenRouter.MaxBatchSize,
+		"openrouter.rate_limit":                  c.OpenRouter.RateLimit,
+		"openrouter.auto_select_model":           c.OpenRouter.AutoSelectModel,
+		"openrouter.concurrency":                 c.OpenRouter.Concurrency,
+		"openrouter.engine":                      c.OpenRouter.Engine,
+		"openrouter.evaluator.provider":          c.OpenRouter.Evaluator.Provider,
+		"openrouter.evaluator.gemini_api_key":    util.MaskSecret(c.OpenRouter.Evaluator.GeminiAPIKey),
+		"openrouter.evaluator.api_key":           util.MaskSecret(c.OpenRouter.Evaluator.APIKey),
+		"openrouter.evaluator.model":             c.OpenRouter.Evaluator.Model,
+		"openrouter.evaluator.schedule_hour":     c.OpenRouter.Evaluator.ScheduleHour,
+		"openrouter.evaluator.mode":              c.OpenRouter.Evaluator.Mode,
+		"openrouter.evaluator.max_candidates":    c.OpenRouter.Evaluator.MaxCandidates,
+		"openrouter.evaluator.scoreboard_path":   c.OpenRouter.Evaluator.ScoreboardPath,
+		"openrouter.evaluator.demote_after":      c.OpenRouter.Evaluator.DemoteAfter,
+		"openrouter.evaluator.candidate_filters": c.OpenRouter.Evaluator.CandidateFilters,
+		"translator.providers":                   c.Translator.Providers,
+		"translator.source_lang":                 c.Translator.SourceLanguage,
+		"translator.target_lang":                 c.Translator.TargetLanguage,
+		"translator.suffix":                      c.Translator.OutputSuffix,
+		"translator.targets":                     c.Translator.Targets,
+		"translator.max_untranslated_ratio":      c.Translator.MaxUntranslatedRatio,
+		"translator.memory.enabled":              c.Translator.Memory.Enabled,
+		"translator.memory.key_prefix":           c.Translator.Memory.KeyPrefix,
+		"translator.memory.ttl":                  c.Translator.Memory.TTL.String(),
+		"translator.bilingual.mode":              c.Translator.Bilingual.Mode,
+		"translator.bilingual.format":            c.Translator.Bilingual.Format,
+		"translator.bilingual.order":             c.Translator.Bilingual.Order,
+		"translator.bilingual.suffix":            c.Translator.Bilingual.Suffix,
+		"translator.ass.font":                    c.Translator.ASS.Font,
+		"translator.output_format":               c.Translator.OutputFormat,
+		"translator.cleanup":                     c.Translator.Cleanup.CleanupRulesConfig,
+		"translator.cleanup.providers":           c.Translator.Cleanup.Providers,
+		"translator.encoding.output":             c.Translator.Encoding.Output,
+		"translator.encoding.bom":                c.Translator.Encoding.BOM,
+		"local_llm.base_url":                     c.LocalLLM.BaseURL,
+		"local_llm.api_key":                      util.MaskSecret(c.LocalLLM.APIKey),
+		"local_llm.model":                        c.LocalLLM.Model,
+		"local_llm.endpoint":                     c.LocalLLM.Endpoint,
+		"local_llm.instruction":                  c.LocalLLM.Instruction,
+		"local_llm.rate_limit":                   c.LocalLLM.RateLimit,
+		"local_llm.max_batch_size":               c.LocalLLM.MaxBatchSize,
+		"local_llm.timeout":                      c.LocalLLM.Timeout.String(),
+		"local_llm.concurrency":                  c.LocalLLM.Concurrency,
+		"local_llm.engine":                       c.LocalLLM.Engine,
+		"worker.concurrency":                     c.Worker.Concurrency,
+		"worker.drain_timeout":                   c.Worker.DrainTimeout.String(),
+		"worker.idempotency_ttl":                 c.Worker.IdempotencyTTL.String(),
+		"worker.priority.enabled":                c.Worker.Priority.Enabled,
+		"worker.priority.media_types":            c.Worker.Priority.MediaTypes,
+		"worker.priority.starvation_limit":       c.Worker.Priority.StarvationLimit,
 	}
 }
@@ -0,0 +1,255 @@
+// Package extract pulls embedded text subtitle tracks out of video files with
+// ffprobe and ffmpeg, for jobs that have no subtitle sidecar.
+package extract
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fusionn-subs/pkg/logger"
+)
+
+// DefaultTimeout bounds a single ffprobe or ffmpeg run.
+const DefaultTimeout = 10 * time.Minute
+
+var (
+	ErrNoTrack    = errors.New("no matching text subtitle track")
+	ErrImageTrack = errors.New("subtitle track is image-based")
+)
+
+// textCodecs are the subtitle codecs ffmpeg can write as text, mapped to the
+// extension of the extracted file.
+var textCodecs = map[string]string{
+	"subrip":   ".srt",
+	"srt":      ".srt",
+	"mov_text": ".srt",
+	"text":     ".srt",
+	"ass":      ".ass",
+	"ssa":      ".ass",
+	"webvtt":   ".vtt",
+}
+
+// englishTags are the language tags of English tracks.
+var englishTags = map[string]bool{"eng": true, "en": true, "english": true}
+
+// Stream is a subtitle stream of a video file as reported by ffprobe.
+type Stream struct {
+	Index           int // Absolute stream index, as in "-map 0:<index>"
+	Codec           string
+	Language        string
+	Title           string
+	Default         bool
+	Forced          bool
+	HearingImpaired bool
+	Frames          int // Cue count from the Matroska statistics tags, 0 if unknown
+}
+
+// IsText reports whether the stream holds text rather than images.
+func (s Stream) IsText() bool {
+	_, ok := textCodecs[s.Codec]
+	return ok
+}
+
+// Ext returns the extension of the file the stream is extracted to.
+func (s Stream) Ext() string {
+	if ext, ok := textCodecs[s.Codec]; ok {
+		return ext
+	}
+	return ".srt"
+}
+
+// IsEnglish reports whether the stream is tagged as English.
+func (s Stream) IsEnglish() bool {
+	return englishTags[strings.ToLower(s.Language)]
+}
+
+// Extractor runs ffprobe and ffmpeg. The binaries are looked up on PATH unless
+// FFPROBE_PATH and FFMPEG_PATH point elsewhere, e.g. at stubs in tests.
+type Extractor struct {
+	ffprobe string
+	ffmpeg  string
+	timeout time.Duration
+}
+
+// New creates an Extractor.
+func New() *Extractor {
+	ffprobe := os.Getenv("FFPROBE_PATH")
+	if ffprobe == "" {
+		ffprobe = "ffprobe"
+	}
+	ffmpeg := os.Getenv("FFMPEG_PATH")
+	if ffmpeg == "" {
+		ffmpeg = "ffmpeg"
+	}
+	return &Extractor{ffprobe: ffprobe, ffmpeg: ffmpeg, timeout: DefaultTimeout}
+}
+
+// Streams lists the subtitle streams of video.
+func (e *Extractor) Streams(ctx context.Context, video string) ([]Stream, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	out, err := e.run(ctx, e.ffprobe,
+		"-v", "error",
+		"-select_streams", "s",
+		"-show_entries", "stream=index,codec_name:stream_tags:stream_disposition=default,forced,hearing_impaired",
+		"-of", "json",
+		video,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe: %w", err)
+	}
+
+	var probe struct {
+		Streams []struct {
+			Index       int               `json:"index"`
+			CodecName   string            `json:"codec_name"`
+			Tags        map[string]string `json:"tags"`
+			Disposition map[string]int    `json:"disposition"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(out, &probe); err != nil {
+		return nil, fmt.Errorf("ffprobe: parse output: %w", err)
+	}
+
+	streams := make([]Stream, 0, len(probe.Streams))
+	for _, p := range probe.Streams {
+		s := Stream{
+			Index:           p.Index,
+			Codec:           strings.ToLower(p.CodecName),
+			Default:         p.Disposition["default"] == 1,
+			Forced:          p.Disposition["forced"] == 1,
+			HearingImpaired: p.Disposition["hearing_impaired"] == 1,
+		}
+		for k, v := range p.Tags {
+			switch {
+			case strings.EqualFold(k, "language"):
+				s.Language = v
+			case strings.EqualFold(k, "title"):
+				s.Title = v
+			case strings.HasPrefix(strings.ToUpper(k), "NUMBER_OF_FRAMES"):
+				s.Frames, _ = strconv.Atoi(v)
+			}
+		}
+		streams = append(streams, s)
+	}
+	return streams, nil
+}
+
+// Select picks the stream to translate. selector is an absolute stream index
+// ("3"), a language tag ("jpn"), or empty for English. Among several
+// candidates full tracks win over forced ones, regular tracks over SDH, the
+// default track over others and longer tracks over shorter ones.
+func Select(streams []Stream, selector string) (Stream, error) {
+	selector = strings.TrimSpace(selector)
+
+	if index, err := strconv.Atoi(selector); err == nil {
+		for _, s := range streams {
+			if s.Index != index {
+				continue
+			}
+			if !s.IsText() {
+				return Stream{}, fmt.Errorf("%w: stream %d (%s)", ErrImageTrack, index, s.Codec)
+			}
+			return s, nil
+		}
+		return Stream{}, fmt.Errorf("%w: no subtitle stream %d", ErrNoTrack, index)
+	}
+
+	var candidates []Stream
+	for _, s := range streams {
+		if !s.IsText() {
+			continue
+		}
+		if selector == "" && s.IsEnglish() || selector != "" && strings.EqualFold(s.Language, selector) {
+			candidates = append(candidates, s)
+		}
+	}
+	if len(candidates) == 0 {
+		language := selector
+		if language == "" {
+			language = "English"
+		}
+		return Stream{}, fmt.Errorf("%w: %s (%d subtitle streams)", ErrNoTrack, language, len(streams))
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := score(candidates[i]), score(candidates[j])
+		if a != b {
+			return a > b
+		}
+		return candidates[i].Frames > candidates[j].Frames
+	})
+	return candidates[0], nil
+}
+
+func score(s Stream) int {
+	title := strings.ToLower(s.Title)
+	n := 0
+	if s.Forced || strings.Contains(title, "forced") || strings.Contains(title, "signs") {
+		n -= 100
+	}
+	if s.HearingImpaired || strings.Contains(title, "sdh") {
+		n -= 10
+	}
+	if s.Default {
+		n += 5
+	}
+	return n
+}
+
+// Extract writes stream s of video to dst as SRT, ASS or WebVTT (see
+// Stream.Ext). The file appears at dst only once ffmpeg has succeeded.
+func (e *Extractor) Extract(ctx context.Context, video string, s Stream, dst string) error {
+	if !s.IsText() {
+		return fmt.Errorf("%w: stream %d (%s)", ErrImageTrack, s.Index, s.Codec)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	format := map[string]string{".srt": "srt", ".ass": "ass", ".vtt": "webvtt"}[s.Ext()]
+	tmp := dst + ".tmp"
+	_, err := e.run(ctx, e.ffmpeg,
+		"-nostdin", "-v", "error", "-y",
+		"-i", video,
+		"-map", "0:"+strconv.Itoa(s.Index),
+		"-c:s", format,
+		"-f", format,
+		tmp,
+	)
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("ffmpeg: %w", err)
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+func (e *Extractor) run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	logger.Debugf("Running %s %s", name, strings.Join(args, " "))
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,208 @@
+package extract
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+const probeOutput = `{
+  "streams": [
+    {"index": 2, "codec_name": "hdmv_pgs_subtitle", "tags": {"language": "eng"}, "disposition": {"default": 1}},
+    {"index": 3, "codec_name": "ASS", "tags": {"language": "eng", "title": "Full", "NUMBER_OF_FRAMES-eng": "812"}, "disposition": {"default": 1, "forced": 0}},
+    {"index": 4, "codec_name": "subrip", "tags": {"LANGUAGE": "jpn", "TITLE": "Signs"}, "disposition": {"forced": 1, "hearing_impaired": 0}},
+    {"index": 5, "codec_name": "subrip", "disposition": {"hearing_impaired": 1}}
+  ]
+}`
+
+// stub writes an executable shell script to dir and returns its path.
+func stub(t *testing.T, dir, name, script string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// newStubExtractor points FFPROBE_PATH and FFMPEG_PATH at stub scripts. The
+// ffmpeg stub records its arguments in args.txt and writes a cue to its last
+// argument, the output file.
+func newStubExtractor(t *testing.T, ffmpegScript string) (*Extractor, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("stub scripts need a POSIX shell")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "probe.json"), []byte(probeOutput), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("FFPROBE_PATH", stub(t, dir, "ffprobe", `cat "$(dirname "$0")/probe.json"`+"\n"))
+	if ffmpegScript == "" {
+		ffmpegScript = `echo "$@" > "$(dirname "$0")/args.txt"
+for last; do :; done
+printf '1\n00:00:01,000 --> 00:00:02,000\nHello.\n' > "$last"
+`
+	}
+	t.Setenv("FFMPEG_PATH", stub(t, dir, "ffmpeg", ffmpegScript))
+	return New(), dir
+}
+
+func TestStreams(t *testing.T) {
+	e, _ := newStubExtractor(t, "")
+	streams, err := e.Streams(context.Background(), "video.mkv")
+	if err != nil {
+		t.Fatalf("Streams: %v", err)
+	}
+
+	want := []Stream{
+		{Index: 2, Codec: "hdmv_pgs_subtitle", Language: "eng", Default: true},
+		{Index: 3, Codec: "ass", Language: "eng", Title: "Full", Default: true, Frames: 812},
+		{Index: 4, Codec: "subrip", Language: "jpn", Title: "Signs", Forced: true},
+		{Index: 5, Codec: "subrip", HearingImpaired: true},
+	}
+	if len(streams) != len(want) {
+		t.Fatalf("got %d streams, want %d: %+v", len(streams), len(want), streams)
+	}
+	for i := range want {
+		if streams[i] != want[i] {
+			t.Errorf("stream %d = %+v, want %+v", i, streams[i], want[i])
+		}
+	}
+}
+
+func TestStreamsProbeFailure(t *testing.T) {
+	_, dir := newStubExtractor(t, "")
+	t.Setenv("FFPROBE_PATH", stub(t, dir, "ffprobe-broken", "echo 'video.mkv: Invalid data found' >&2\nexit 1\n"))
+	e := New()
+
+	_, err := e.Streams(context.Background(), "video.mkv")
+	if err == nil || !strings.Contains(err.Error(), "Invalid data found") {
+		t.Errorf("err = %v, want ffprobe's message", err)
+	}
+}
+
+func TestExtract(t *testing.T) {
+	e, dir := newStubExtractor(t, "")
+	dst := filepath.Join(dir, "video.en.ass")
+
+	err := e.Extract(context.Background(), "video.mkv", Stream{Index: 3, Codec: "ass"}, dst)
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if data, err := os.ReadFile(dst); err != nil || !strings.Contains(string(data), "Hello.") {
+		t.Errorf("extracted file = %q, %v", data, err)
+	}
+	if _, err := os.Stat(dst + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary file left behind: %v", err)
+	}
+
+	args, err := os.ReadFile(filepath.Join(dir, "args.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"-i video.mkv", "-map 0:3", "-c:s ass", "-f ass"} {
+		if !strings.Contains(string(args), want) {
+			t.Errorf("ffmpeg arguments %q lack %q", strings.TrimSpace(string(args)), want)
+		}
+	}
+}
+
+func TestExtractFailure(t *testing.T) {
+	e, dir := newStubExtractor(t, `for last; do :; done
+echo partial > "$last"
+echo 'Stream map matches no streams' >&2
+exit 1
+`)
+	dst := filepath.Join(dir, "video.en.srt")
+
+	err := e.Extract(context.Background(), "video.mkv", Stream{Index: 9, Codec: "subrip"}, dst)
+	if err == nil || !strings.Contains(err.Error(), "matches no streams") {
+		t.Errorf("err = %v, want ffmpeg's message", err)
+	}
+	for _, path := range []string{dst, dst + ".tmp"} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s exists after a failed extraction", filepath.Base(path))
+		}
+	}
+}
+
+func TestExtractImageTrack(t *testing.T) {
+	e, dir := newStubExtractor(t, "")
+	err := e.Extract(context.Background(), "video.mkv", Stream{Index: 2, Codec: "hdmv_pgs_subtitle"}, filepath.Join(dir, "out.srt"))
+	if !errors.Is(err, ErrImageTrack) {
+		t.Errorf("err = %v, want %v", err, ErrImageTrack)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "args.txt")); !os.IsNotExist(err) {
+		t.Error("ffmpeg ran for an image track")
+	}
+}
+
+func TestSelect(t *testing.T) {
+	streams := []Stream{
+		{Index: 2, Codec: "hdmv_pgs_subtitle", Language: "eng", Default: true},
+		{Index: 3, Codec: "subrip", Language: "eng", Title: "English (Forced)", Frames: 40},
+		{Index: 4, Codec: "subrip", Language: "eng", Title: "SDH", Frames: 900},
+		{Index: 5, Codec: "subrip", Language: "en", Frames: 850},
+		{Index: 6, Codec: "subrip", Language: "eng", Frames: 700},
+		{Index: 7, Codec: "ass", Language: "jpn", Title: "Signs"},
+		{Index: 8, Codec: "ass", Language: "jpn", Default: true},
+	}
+
+	tests := []struct {
+		name     string
+		streams  []Stream
+		selector string
+		want     int
+		err      error
+	}{
+		{"English by default, longest regular track", streams, "", 5, nil},
+		{"language tag", streams, "JPN", 8, nil},
+		{"stream index", streams, "4", 4, nil},
+		{"stream index with spaces", streams, " 6 ", 6, nil},
+		{"image track by index", streams, "2", 0, ErrImageTrack},
+		{"unknown index", streams, "42", 0, ErrNoTrack},
+		{"unknown language", streams, "fre", 0, ErrNoTrack},
+		{"only image tracks", streams[:1], "", 0, ErrNoTrack},
+		{"forced only as last resort", streams[1:2], "", 3, nil},
+		{"default beats longer", []Stream{
+			{Index: 1, Codec: "subrip", Language: "eng", Frames: 900},
+			{Index: 2, Codec: "subrip", Language: "eng", Frames: 800, Default: true},
+		}, "", 2, nil},
+		{"regular beats hearing impaired", []Stream{
+			{Index: 1, Codec: "subrip", Language: "eng", HearingImpaired: true, Default: true},
+			{Index: 2, Codec: "subrip", Language: "eng"},
+		}, "", 2, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Select(tt.streams, tt.selector)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("err = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Select: %v", err)
+			}
+			if got.Index != tt.want {
+				t.Errorf("selected stream %d, want %d", got.Index, tt.want)
+			}
+		})
+	}
+}
+
+func TestStreamExt(t *testing.T) {
+	tests := map[string]string{"subrip": ".srt", "mov_text": ".srt", "ass": ".ass", "ssa": ".ass", "webvtt": ".vtt"}
+	for codec, want := range tests {
+		if got := (Stream{Codec: codec}).Ext(); got != want {
+			t.Errorf("Ext(%s) = %q, want %q", codec, got, want)
+		}
+	}
+}
@@ -0,0 +1,416 @@
+package modelselection
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/fusionn-subs/internal/client/openai"
+	"github.com/fusionn-subs/internal/client/openrouter"
+	"github.com/fusionn-subs/internal/service/translator"
+	"github.com/fusionn-subs/internal/subtitle"
+	"github.com/fusionn-subs/pkg/logger"
+)
+
+//go:embed samples/benchmark.srt
+var benchmarkSample []byte
+
+//go:embed prompts/benchmark_judge.tmpl
+var judgePromptTemplate string
+
+var judgePrompt = template.Must(template.New("benchmark_judge").Parse(judgePromptTemplate))
+
+const (
+	defaultMaxCandidates = 6
+	benchmarkCallTimeout = 90 * time.Second // Per candidate; slower models lose on latency anyway
+)
+
+// BenchmarkResult is one candidate's row in the scoreboard.
+type BenchmarkResult struct {
+	Model     string  `json:"model"`
+	LatencyMs int64   `json:"latency_ms"`
+	FormatOK  bool    `json:"format_ok"` // Reply parsed as the batch JSON format
+	Coverage  float64 `json:"coverage"`  // Share of sample cues returned translated
+	Fidelity  float64 `json:"fidelity"`  // Judge score, 0-10
+	Score     float64 `json:"score"`     // Weighted total, 0-100
+	Error     string  `json:"error,omitempty"`
+}
+
+// Scoreboard is the outcome of one benchmark run.
+type Scoreboard struct {
+	EvaluatedAt    time.Time         `json:"evaluated_at"`
+	TargetLanguage string            `json:"target_language"`
+	Judge          string            `json:"judge"`
+	Winner         string            `json:"winner"`
+	Results        []BenchmarkResult `json:"results"`
+}
+
+// BenchmarkConfig configures a BenchmarkEvaluator.
+type BenchmarkConfig struct {
+	OpenRouterAPIKey string
+	Judge            LLM // Scores translation fidelity
+	SourceLanguage   string
+	TargetLanguage   string
+	MaxCandidates    int    // Free models benchmarked per run (default: 6)
+	ScoreboardPath   string // JSON file the latest scoreboard is written to ("" = log only)
+}
+
+// BenchmarkEvaluator selects a model by translating an embedded reference
+// subtitle with each candidate and scoring the results, instead of judging
+// model metadata alone.
+type BenchmarkEvaluator struct {
+	chat           *openai.Client
+	judge          LLM
+	sourceLanguage string
+	targetLanguage string
+	maxCandidates  int
+	scoreboardPath string
+	sample         *subtitle.Subtitle
+
+	mu   sync.RWMutex
+	last *Scoreboard
+}
+
+// NewBenchmarkEvaluator creates a benchmark-based model evaluator.
+func NewBenchmarkEvaluator(cfg BenchmarkConfig) (*BenchmarkEvaluator, error) {
+	sample, err := subtitle.ParseSRT(benchmarkSample)
+	if err != nil {
+		return nil, fmt.Errorf("parse benchmark sample: %w", err)
+	}
+
+	if cfg.SourceLanguage == "" {
+		cfg.SourceLanguage = "English"
+	}
+	if cfg.TargetLanguage == "" {
+		cfg.TargetLanguage = "Chinese"
+	}
+	if cfg.MaxCandidates <= 0 {
+		cfg.MaxCandidates = defaultMaxCandidates
+	}
+
+	return &BenchmarkEvaluator{
+		chat:           openai.NewClient(openrouter.DefaultBaseURL, "/chat/completions", cfg.OpenRouterAPIKey, benchmarkCallTimeout),
+		judge:          cfg.Judge,
+		sourceLanguage: cfg.SourceLanguage,
+		targetLanguage: cfg.TargetLanguage,
+		maxCandidates:  cfg.MaxCandidates,
+		scoreboardPath: cfg.ScoreboardPath,
+		sample:         sample,
+	}, nil
+}
+
+// LastScoreboard returns the most recent scoreboard, or nil before the first run.
+func (e *BenchmarkEvaluator) LastScoreboard() *Scoreboard {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.last
+}
+
+// candidateRun is a benchmark result plus the translated lines by cue id.
+type candidateRun struct {
+	result BenchmarkResult
+	lines  map[int]string
+}
+
+// SelectBestModel benchmarks the most promising free models and returns the
+// highest scoring one.
+func (e *BenchmarkEvaluator) SelectBestModel(models []openrouter.Model) (string, error) {
+	candidates := pickCandidates(models, e.maxCandidates)
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("no candidate models to benchmark")
+	}
+
+	logger.Infof("🏁 Benchmarking %d models on a %d-cue sample (%s)", len(candidates), len(e.sample.Cues), e.targetLanguage)
+
+	ctx := context.Background()
+	system, user, err := translator.BatchPrompt(e.targetLanguage, e.sample)
+	if err != nil {
+		return "", err
+	}
+
+	runs := make([]*candidateRun, len(candidates))
+	for i, m := range candidates {
+		runs[i] = e.runCandidate(ctx, m.ID, system, user)
+	}
+
+	if err := e.judgeRuns(ctx, runs); err != nil {
+		logger.Warnf("⚠️  Fidelity judge failed, ranking without it: %v", err)
+	}
+
+	board := &Scoreboard{
+		EvaluatedAt:    time.Now().UTC(),
+		TargetLanguage: e.targetLanguage,
+		Judge:          e.judge.Name(),
+		Results:        make([]BenchmarkResult, len(runs)),
+	}
+	for i, r := range runs {
+		r.result.Score = score(r.result)
+		board.Results[i] = r.result
+	}
+	sort.SliceStable(board.Results, func(i, j int) bool {
+		a, b := board.Results[i], board.Results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.LatencyMs < b.LatencyMs
+	})
+	if best := board.Results[0]; best.Score > 0 {
+		board.Winner = best.Model
+	}
+
+	e.mu.Lock()
+	e.last = board
+	e.mu.Unlock()
+
+	logScoreboard(board)
+	if err := e.saveScoreboard(board); err != nil {
+		logger.Warnf("Failed to save scoreboard: %v", err)
+	}
+
+	if board.Winner == "" {
+		return "", fmt.Errorf("no candidate produced a usable translation")
+	}
+	logger.Infof("✅ Selected model: %s (score %.1f)", board.Winner, board.Results[0].Score)
+	return board.Winner, nil
+}
+
+// pickCandidates drops code-focused models and keeps the ones with the
+// largest context windows, which tend to be the general-purpose flagships.
+func pickCandidates(models []openrouter.Model, limit int) []openrouter.Model {
+	filtered := make([]openrouter.Model, 0, len(models))
+	for _, m := range models {
+		id := strings.ToLower(m.ID)
+		if strings.Contains(id, "code") || strings.Contains(id, "coder") {
+			continue
+		}
+		filtered = append(filtered, m)
+	}
+	sort.SliceStable(filtered, func(i, j int) bool { return filtered[i].ContextLen > filtered[j].ContextLen })
+	if len(filtered) > limit {
+		filtered = filtered[:limit]
+	}
+	return filtered
+}
+
+// runCandidate translates the sample with one model and measures format
+// adherence, cue coverage and latency.
+func (e *BenchmarkEvaluator) runCandidate(ctx context.Context, model, system, user string) *candidateRun {
+	run := &candidateRun{result: BenchmarkResult{Model: model}}
+
+	ctx, cancel := context.WithTimeout(ctx, benchmarkCallTimeout)
+	defer cancel()
+
+	start := time.Now()
+	reply, err := e.chat.Complete(ctx, openai.ChatRequest{
+		Model: model,
+		Messages: []openai.Message{
+			{Role: "system", Content: system},
+			{Role: "user", Content: user},
+		},
+		Temperature: 0.3,
+	})
+	run.result.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		run.result.Error = err.Error()
+		logger.Infof("   ✗ %s: %v", model, err)
+		return run
+	}
+
+	lines, ok := parseSampleReply(reply)
+	run.result.FormatOK = ok
+	run.lines = lines
+
+	translated, total := 0, 0
+	for i, c := range e.sample.Cues {
+		if c.IsEmpty() {
+			continue
+		}
+		total++
+		if text := lines[i+1]; text != "" && text != c.Text() {
+			translated++
+		}
+	}
+	if total > 0 {
+		run.result.Coverage = float64(translated) / float64(total)
+	}
+
+	logger.Infof("   ✓ %s: %dms, format ok: %v, coverage: %.0f%%", model, run.result.LatencyMs, ok, run.result.Coverage*100)
+	return run
+}
+
+// parseSampleReply is a lenient version of the native engine's reply parser:
+// it keeps whatever lines were returned so partial replies can be scored.
+func parseSampleReply(reply string) (map[int]string, bool) {
+	start := strings.Index(reply, "{")
+	end := strings.LastIndex(reply, "}")
+	if start == -1 || end < start {
+		return nil, false
+	}
+
+	var parsed struct {
+		Translations []struct {
+			ID   int    `json:"id"`
+			Text string `json:"text"`
+		} `json:"translations"`
+	}
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &parsed); err != nil {
+		return nil, false
+	}
+
+	lines := make(map[int]string, len(parsed.Translations))
+	for _, t := range parsed.Translations {
+		lines[t.ID] = strings.TrimSpace(t.Text)
+	}
+	return lines, true
+}
+
+type judgeLine struct {
+	ID   int
+	Text string
+}
+
+type judgeCandidate struct {
+	Label string
+	Lines []judgeLine
+}
+
+// judgeRuns asks the judge model to score all usable translations in one
+// request. Candidates are anonymized so model names cannot bias the judge.
+func (e *BenchmarkEvaluator) judgeRuns(ctx context.Context, runs []*candidateRun) error {
+	var source []judgeLine
+	for i, c := range e.sample.Cues {
+		if !c.IsEmpty() {
+			source = append(source, judgeLine{ID: i + 1, Text: c.Text()})
+		}
+	}
+
+	byLabel := make(map[string]*candidateRun)
+	var candidates []judgeCandidate
+	for _, r := range runs {
+		if !r.result.FormatOK {
+			continue
+		}
+		label := string(rune('A' + len(candidates)))
+		byLabel[label] = r
+
+		jc := judgeCandidate{Label: label}
+		for _, l := range source {
+			jc.Lines = append(jc.Lines, judgeLine{ID: l.ID, Text: r.lines[l.ID]})
+		}
+		candidates = append(candidates, jc)
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	var prompt bytes.Buffer
+	err := judgePrompt.Execute(&prompt, struct {
+		SourceLanguage string
+		TargetLanguage string
+		Source         []judgeLine
+		Candidates     []judgeCandidate
+	}{e.sourceLanguage, e.targetLanguage, source, candidates})
+	if err != nil {
+		return fmt.Errorf("build judge prompt: %w", err)
+	}
+
+	reply, err := e.judge.Generate(ctx, Prompt{
+		System:      "You are a strict, impartial judge of subtitle translation quality.",
+		User:        prompt.String(),
+		Temperature: 0.1,
+		Schema:      judgeResponseSchema,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Models without schema support may wrap the JSON in prose or fences
+	if start, end := strings.Index(reply, "{"), strings.LastIndex(reply, "}"); start != -1 && end > start {
+		reply = reply[start : end+1]
+	}
+
+	var parsed struct {
+		Scores []struct {
+			Candidate string  `json:"candidate"`
+			Score     float64 `json:"score"`
+		} `json:"scores"`
+	}
+	if err := json.Unmarshal([]byte(reply), &parsed); err != nil {
+		return fmt.Errorf("parse judge reply: %w", err)
+	}
+	for _, s := range parsed.Scores {
+		if r, ok := byLabel[strings.TrimSpace(s.Candidate)]; ok {
+			r.result.Fidelity = min(max(s.Score, 0), 10)
+		}
+	}
+	return nil
+}
+
+var judgeResponseSchema = map[string]any{
+	"type": "OBJECT",
+	"properties": map[string]any{
+		"scores": map[string]any{
+			"type": "ARRAY",
+			"items": map[string]any{
+				"type": "OBJECT",
+				"properties": map[string]any{
+					"candidate": map[string]any{"type": "STRING"},
+					"score":     map[string]any{"type": "NUMBER"},
+				},
+				"required": []string{"candidate", "score"},
+			},
+		},
+	},
+	"required": []string{"scores"},
+}
+
+// score weighs the measurements into a 0-100 total. A reply that does not
+// follow the format is unusable in production and scores zero.
+func score(r BenchmarkResult) float64 {
+	if !r.FormatOK {
+		return 0
+	}
+	latency := max(0, 1-float64(r.LatencyMs)/float64(benchmarkCallTimeout.Milliseconds()))
+	return 100 * (0.15 + 0.25*r.Coverage + 0.5*r.Fidelity/10 + 0.1*latency)
+}
+
+func logScoreboard(b *Scoreboard) {
+	logger.Infof("📊 Benchmark scoreboard (judge: %s):", b.Judge)
+	for i, r := range b.Results {
+		if r.Error != "" {
+			logger.Infof("   %d. %-50s error: %s", i+1, r.Model, r.Error)
+			continue
+		}
+		logger.Infof("   %d. %-50s score %5.1f | fidelity %4.1f | coverage %3.0f%% | format %-5v | %dms",
+			i+1, r.Model, r.Score, r.Fidelity, r.Coverage*100, r.FormatOK, r.LatencyMs)
+	}
+}
+
+func (e *BenchmarkEvaluator) saveScoreboard(b *Scoreboard) error {
+	if e.scoreboardPath == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(e.scoreboardPath), 0o755); err != nil {
+		return err
+	}
+
+	tmp := e.scoreboardPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, e.scoreboardPath)
+}
@@ -0,0 +1,159 @@
+package modelselection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fusionn-subs/internal/client/openrouter"
+	"github.com/fusionn-subs/pkg/logger"
+)
+
+const (
+	defaultDemoteAfter = 3
+
+	// blacklistDuration keeps a demoted model out of selection until roughly
+	// the next daily evaluation.
+	blacklistDuration = 24 * time.Hour
+)
+
+// Ranker is implemented by evaluators that rank every candidate, not just the
+// winner. The ranking is used to promote a replacement when the selected
+// model keeps failing.
+type Ranker interface {
+	Ranking() []string
+}
+
+// Ranking returns the models of the last benchmark that produced usable
+// output, best first.
+func (e *BenchmarkEvaluator) Ranking() []string {
+	board := e.LastScoreboard()
+	if board == nil {
+		return nil
+	}
+	var ranking []string
+	for _, r := range board.Results {
+		if r.Score > 0 {
+			ranking = append(ranking, r.Model)
+		}
+	}
+	return ranking
+}
+
+// rankingFor returns the candidates in order of preference, starting with the
+// selected model. Evaluators that only name a winner are complemented with the
+// remaining models ordered by the same heuristic as benchmark candidates.
+func rankingFor(evaluator Evaluator, selected string, models []openrouter.Model) []string {
+	ranking := []string{selected}
+	seen := map[string]bool{selected: true}
+
+	var rest []string
+	if r, ok := evaluator.(Ranker); ok {
+		rest = r.Ranking()
+	} else {
+		for _, m := range pickCandidates(models, len(models)) {
+			rest = append(rest, m.ID)
+		}
+	}
+	for _, id := range rest {
+		if !seen[id] {
+			seen[id] = true
+			ranking = append(ranking, id)
+		}
+	}
+	return ranking
+}
+
+// withoutBlacklisted drops demoted models from an evaluation and forgets
+// blacklist entries that have expired.
+func (s *Selector) withoutBlacklisted(models []openrouter.Model) []openrouter.Model {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for m, until := range s.blacklist {
+		if now.After(until) {
+			delete(s.blacklist, m)
+		}
+	}
+	if len(s.blacklist) == 0 {
+		return models
+	}
+
+	kept := make([]openrouter.Model, 0, len(models))
+	for _, m := range models {
+		if _, banned := s.blacklist[m.ID]; banned {
+			logger.Infof("🚫 Skipping demoted model: %s", m.ID)
+			continue
+		}
+		kept = append(kept, m)
+	}
+	return kept
+}
+
+// ReportOutcome feeds back the result of a job translated with model. After
+// DemoteAfter consecutive failures (including rate limits) of the selected
+// model it is blacklisted and the next-ranked candidate takes over at once.
+// Outcomes for models other than the selected one are ignored.
+func (s *Selector) ReportOutcome(model string, err error) {
+	if errors.Is(err, context.Canceled) {
+		return // Shutdown, not the model's fault
+	}
+
+	s.mu.Lock()
+	if model == "" || model != s.selected {
+		s.mu.Unlock()
+		return
+	}
+
+	if err == nil {
+		reset := s.failures[model] > 0
+		s.failures[model] = 0
+		s.mu.Unlock()
+		if reset {
+			s.persist()
+		}
+		return
+	}
+
+	s.failures[model]++
+	count := s.failures[model]
+	if count < s.demoteAfter {
+		s.mu.Unlock()
+		logger.Warnf("⚠️  Model %s failed (%d/%d before demotion): %v", model, count, s.demoteAfter, err)
+		s.persist()
+		return
+	}
+
+	s.blacklist[model] = time.Now().Add(blacklistDuration)
+	next := s.nextCandidateLocked()
+	s.selected = next
+	s.lastKnown = next
+	s.failures[next] = 0
+	s.recordLocked(next, fmt.Sprintf("demoted %s after %d consecutive failures: %v", model, count, err))
+	callbacks := s.callbacks
+	s.mu.Unlock()
+
+	logger.Warnf("⬇️  Model %s demoted after %d consecutive failures, switching to %s", model, count, next)
+	s.persist()
+
+	for _, cb := range callbacks {
+		cb(next)
+	}
+}
+
+// nextCandidateLocked returns the best-ranked model that is neither
+// blacklisted nor current, falling back to the configured default. Caller
+// must hold s.mu.
+func (s *Selector) nextCandidateLocked() string {
+	for _, m := range s.ranking {
+		if m == s.selected {
+			continue
+		}
+		if _, banned := s.blacklist[m]; !banned {
+			return m
+		}
+	}
+	return s.defaultModel
+}
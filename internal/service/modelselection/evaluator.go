@@ -8,9 +8,6 @@ import (
 	"regexp"
 	"strings"
 	"text/template"
-	"time"
-
-	"github.com/go-resty/resty/v2"
 
 	"github.com/fusionn-subs/internal/client/openrouter"
 	"github.com/fusionn-subs/pkg/logger"
@@ -24,44 +21,22 @@ type Evaluator interface {
 	SelectBestModel(models []openrouter.Model) (string, error)
 }
 
-// GeminiEvaluator uses Google Gemini to evaluate and select models via REST API.
-type GeminiEvaluator struct {
-	apiKey  string
-	model   string
-	baseURL string
-	client  *resty.Client
+// MetadataEvaluator asks an evaluator model to pick the best translation model
+// from OpenRouter model metadata.
+type MetadataEvaluator struct {
+	llm            LLM
+	sourceLanguage string
+	targetLanguage string
 }
 
-// NewGeminiEvaluator creates a Gemini-based model evaluator.
-func NewGeminiEvaluator(apiKey, model string) *GeminiEvaluator {
-	if model == "" {
-		model = "gemini-3-flash-preview"
-	}
-	return &GeminiEvaluator{
-		apiKey:  apiKey,
-		model:   model,
-		baseURL: "https://generativelanguage.googleapis.com/v1beta/models",
-		client: resty.New().
-			SetTimeout(3*time.Minute). // Generous timeout for high thinking level + search grounding
-			SetHeader("Content-Type", "application/json").
-			SetRetryCount(3).                      // Retry up to 3 times for transient failures
-			SetRetryWaitTime(5 * time.Second).     // Initial wait: 5s
-			SetRetryMaxWaitTime(30 * time.Second). // Max wait: 30s (exponential backoff)
-			AddRetryCondition(func(r *resty.Response, err error) bool {
-				// Retry on 429 (rate limit) or 5xx (server errors)
-				return r.StatusCode() == 429 || r.StatusCode() >= 500
-			}).
-			OnAfterResponse(func(c *resty.Client, r *resty.Response) error {
-				if r.Request.Attempt > 0 {
-					logger.Warnf("⚠️  Gemini API retry attempt #%d (status: %d)", r.Request.Attempt, r.StatusCode())
-				}
-				return nil
-			}),
-	}
+// NewMetadataEvaluator creates a metadata-based model evaluator for
+// translating from sourceLang into targetLang.
+func NewMetadataEvaluator(llm LLM, sourceLang, targetLang string) *MetadataEvaluator {
+	return &MetadataEvaluator{llm: llm, sourceLanguage: sourceLang, targetLanguage: targetLang}
 }
 
-// SelectBestModel uses Gemini to evaluate and select the best translation model.
-func (e *GeminiEvaluator) SelectBestModel(models []openrouter.Model) (string, error) {
+// SelectBestModel asks the evaluator model to select the best translation model.
+func (e *MetadataEvaluator) SelectBestModel(models []openrouter.Model) (string, error) {
 	if len(models) == 0 {
 		return "", fmt.Errorf("no models provided")
 	}
@@ -76,7 +51,7 @@ func (e *GeminiEvaluator) SelectBestModel(models []openrouter.Model) (string, er
 	for i, m := range models {
 		modelIDs[i] = m.ID
 	}
-	logger.Infof("🤔 Evaluating %d models with %s", len(models), e.model)
+	logger.Infof("🤔 Evaluating %d models with %s", len(models), e.llm.Name())
 	logger.Infof("📋 Models to evaluate: %v", modelIDs)
 
 	// Log first part of prompt for debugging
@@ -84,9 +59,9 @@ func (e *GeminiEvaluator) SelectBestModel(models []openrouter.Model) (string, er
 	logger.Debugf("📝 Evaluation prompt:\n%s", promptPreview)
 
 	ctx := context.Background()
-	selectedModel, err := e.callGemini(ctx, prompt)
+	selectedModel, err := e.callEvaluator(ctx, prompt)
 	if err != nil {
-		return "", fmt.Errorf("gemini evaluation failed: %w", err)
+		return "", fmt.Errorf("%s evaluation failed: %w", e.llm.Name(), err)
 	}
 
 	// Validate selected model exists in the list
@@ -107,7 +82,7 @@ func (e *GeminiEvaluator) SelectBestModel(models []openrouter.Model) (string, er
 	}
 
 	if len(suggestions) > 0 {
-		logger.Warnf("⚠️  Gemini returned invalid model '%s', possible matches: %v", selectedModel, suggestions)
+		logger.Warnf("⚠️  Evaluator returned invalid model '%s', possible matches: %v", selectedModel, suggestions)
 		// Use first suggestion
 		logger.Infof("✅ Using closest match: %s", suggestions[0])
 		return suggestions[0], nil
@@ -124,16 +99,20 @@ func (e *GeminiEvaluator) SelectBestModel(models []openrouter.Model) (string, er
 }
 
 // buildEvaluationPrompt constructs the evaluation prompt with model metadata using a template.
-func (e *GeminiEvaluator) buildEvaluationPrompt(models []openrouter.Model) (string, error) {
+func (e *MetadataEvaluator) buildEvaluationPrompt(models []openrouter.Model) (string, error) {
 	tmpl, err := template.New("evaluation").Parse(evaluationPromptTemplate)
 	if err != nil {
 		return "", fmt.Errorf("parse template: %w", err)
 	}
 
 	data := struct {
-		Models []openrouter.Model
+		Models         []openrouter.Model
+		SourceLanguage string
+		TargetLanguage string
 	}{
-		Models: models,
+		Models:         models,
+		SourceLanguage: e.sourceLanguage,
+		TargetLanguage: e.targetLanguage,
 	}
 
 	var buf bytes.Buffer
@@ -144,79 +123,27 @@ func (e *GeminiEvaluator) buildEvaluationPrompt(models []openrouter.Model) (stri
 	return buf.String(), nil
 }
 
-// callGemini sends a prompt to Gemini using REST API with Gemini 3 features.
-// Reference: https://ai.google.dev/gemini-api/docs/gemini-3
-func (e *GeminiEvaluator) callGemini(ctx context.Context, prompt string) (string, error) {
-	url := fmt.Sprintf("%s/%s:generateContent", e.baseURL, e.model)
-
-	// Build request with Gemini 3 features
-	requestBody := map[string]any{
-		"system_instruction": map[string]any{
-			"parts": []map[string]string{
-				{
-					"text": "You are an AI model evaluation expert. Select the BEST model for English to Chinese subtitle translation from the provided list. Respond with ONLY the model ID, no explanations or reasoning.",
-				},
-			},
-		},
-		"contents": []map[string]any{
-			{
-				"parts": []map[string]string{
-					{"text": prompt},
-				},
-			},
-		},
-		"generationConfig": map[string]any{
-			"temperature": 0.1,
-		},
-		// Note: Google Search is intentionally NOT enabled here.
-		// For model selection, we're comparing a known list of models, not researching unknown information.
-		// Enabling search causes verbose, research-style responses instead of concise model IDs.
-	}
-
-	// Response structure
-	var result struct {
-		Candidates []struct {
-			Content struct {
-				Parts []struct {
-					Text string `json:"text"`
-				} `json:"parts"`
-			} `json:"content"`
-		} `json:"candidates"`
-		Error struct {
-			Message string `json:"message"`
-			Code    int    `json:"code"`
-		} `json:"error"`
-	}
-
-	resp, err := e.client.R().
-		SetContext(ctx).
-		SetQueryParam("key", e.apiKey).
-		SetBody(requestBody).
-		SetResult(&result).
-		SetError(&result).
-		Post(url)
-
+// callEvaluator sends the prompt to the evaluator model and extracts a model ID
+// from its reply.
+func (e *MetadataEvaluator) callEvaluator(ctx context.Context, prompt string) (string, error) {
+	// Note: web search is intentionally NOT enabled here (Gemini supports it).
+	// For model selection, we're comparing a known list of models, not researching unknown information.
+	// Enabling search causes verbose, research-style responses instead of concise model IDs.
+	rawResponse, err := e.llm.Generate(ctx, Prompt{
+		System: fmt.Sprintf("You are an AI model evaluation expert. Select the BEST model for %s to %s subtitle translation from the provided list. Respond with ONLY the model ID, no explanations or reasoning.",
+			e.sourceLanguage, e.targetLanguage),
+		User:        prompt,
+		Temperature: 0.1,
+	})
 	if err != nil {
-		return "", fmt.Errorf("http request: %w", err)
-	}
-
-	if !resp.IsSuccess() {
-		if result.Error.Message != "" {
-			return "", fmt.Errorf("API error %d: %s", result.Error.Code, result.Error.Message)
-		}
-		return "", fmt.Errorf("API error %d: %s", resp.StatusCode(), resp.String())
-	}
-
-	if len(result.Candidates) == 0 || len(result.Candidates[0].Content.Parts) == 0 {
-		return "", fmt.Errorf("no response from Gemini")
+		return "", err
 	}
 
-	rawResponse := result.Candidates[0].Content.Parts[0].Text
-	logger.Infof("💬 Gemini raw response: %q", rawResponse)
+	logger.Infof("💬 Evaluator raw response: %q", rawResponse)
 
 	// Warn if response is verbose (indicates prompt not followed)
 	if len(rawResponse) > 100 {
-		logger.Warnf("⚠️  Gemini returned verbose response (%d chars) instead of just model ID", len(rawResponse))
+		logger.Warnf("⚠️  Evaluator returned verbose response (%d chars) instead of just model ID", len(rawResponse))
 	}
 
 	selectedModel := strings.TrimSpace(rawResponse)
@@ -0,0 +1,118 @@
+package modelselection
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fusionn-subs/internal/client/gemini"
+	"github.com/fusionn-subs/internal/client/openai"
+	"github.com/fusionn-subs/internal/client/openrouter"
+)
+
+// Evaluator providers.
+const (
+	ProviderGemini     = "gemini"
+	ProviderOpenRouter = "openrouter"
+	ProviderLocalLLM   = "local_llm"
+)
+
+// evaluatorTimeout is generous: evaluator models may think for a while.
+const evaluatorTimeout = 3 * time.Minute
+
+// Prompt is a single request to an evaluator model.
+type Prompt struct {
+	System      string
+	User        string
+	Temperature float64
+	Schema      map[string]any // JSON response schema; only enforced by Gemini, others rely on the prompt
+}
+
+// LLM is the model an evaluator consults: to pick from model metadata, or to
+// judge benchmark translations.
+type LLM interface {
+	Generate(ctx context.Context, p Prompt) (string, error)
+	Name() string // For logs, e.g. "gemini/gemini-3-flash"
+}
+
+// EvaluatorLLMConfig selects and configures the evaluator model.
+type EvaluatorLLMConfig struct {
+	Provider string // "gemini" (default), "openrouter" or "local_llm"
+	APIKey   string
+	Model    string
+	BaseURL  string // local_llm only
+	Endpoint string // local_llm only (default: /v1/chat/completions)
+}
+
+// NewLLM creates the evaluator model client for cfg.Provider.
+func NewLLM(cfg EvaluatorLLMConfig) (LLM, error) {
+	switch cfg.Provider {
+	case "", ProviderGemini:
+		if cfg.Model == "" {
+			cfg.Model = "gemini-3-flash-preview"
+		}
+		return &geminiLLM{client: gemini.NewClient(cfg.APIKey, evaluatorTimeout), model: cfg.Model}, nil
+	case ProviderOpenRouter:
+		if cfg.Model == "" {
+			return nil, fmt.Errorf("evaluator model required for openrouter")
+		}
+		return &chatLLM{
+			provider: ProviderOpenRouter,
+			client:   openai.NewClient(openrouter.DefaultBaseURL, "/chat/completions", cfg.APIKey, evaluatorTimeout),
+			model:    cfg.Model,
+		}, nil
+	case ProviderLocalLLM:
+		if cfg.BaseURL == "" {
+			return nil, fmt.Errorf("evaluator base URL required for local_llm")
+		}
+		endpoint := cfg.Endpoint
+		if endpoint == "" {
+			endpoint = "/v1/chat/completions"
+		}
+		return &chatLLM{
+			provider: ProviderLocalLLM,
+			client:   openai.NewClient(cfg.BaseURL, endpoint, cfg.APIKey, evaluatorTimeout),
+			model:    cfg.Model,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown evaluator provider: %q", cfg.Provider)
+	}
+}
+
+// geminiLLM calls the Gemini generateContent API.
+type geminiLLM struct {
+	client *gemini.Client
+	model  string
+}
+
+func (g *geminiLLM) Generate(ctx context.Context, p Prompt) (string, error) {
+	return g.client.GenerateContent(ctx, g.model, gemini.Request{
+		SystemInstruction: p.System,
+		Prompt:            p.User,
+		Temperature:       p.Temperature,
+		ResponseSchema:    p.Schema,
+	})
+}
+
+func (g *geminiLLM) Name() string { return ProviderGemini + "/" + g.model }
+
+// chatLLM calls an OpenAI-compatible chat completions API (OpenRouter or a
+// local server).
+type chatLLM struct {
+	provider string
+	client   *openai.Client
+	model    string
+}
+
+func (c *chatLLM) Generate(ctx context.Context, p Prompt) (string, error) {
+	return c.client.Complete(ctx, openai.ChatRequest{
+		Model: c.model,
+		Messages: []openai.Message{
+			{Role: "system", Content: p.System},
+			{Role: "user", Content: p.User},
+		},
+		Temperature: p.Temperature,
+	})
+}
+
+func (c *chatLLM) Name() string { return c.provider + "/" + c.model }
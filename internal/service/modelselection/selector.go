@@ -3,6 +3,7 @@ package modelselection
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -18,24 +19,66 @@ type Selector struct {
 	openRouterClient *openrouter.Client
 	evaluator        Evaluator
 	defaultModel     string
-	scheduleHour     int // Hour of day (0-23) to run evaluation
+	scheduleHour     int           // Hour of day (0-23) to run evaluation
+	reuseWithin      time.Duration // Max age of a restored selection that skips startup evaluation
+	demoteAfter      int           // Consecutive failures before the selected model is demoted
+	filter           CandidateFilter
+
+	store       StateStore // Optional; nil keeps state in memory only
+	fingerprint string     // Identifies the evaluation settings, see Config.fingerprint
 
 	mu           sync.RWMutex
 	selected     string
 	lastKnown    string
 	lastEvalTime time.Time
+	evalFailures int
+	history      []Selection
+	ranking      []string             // Candidates of the last evaluation, best first
+	failures     map[string]int       // Consecutive job failures per model
+	blacklist    map[string]time.Time // Demoted models and when they may be used again
 
 	callbacks []ModelUpdateCallback
 	stop      chan struct{}
+	stopOnce  sync.Once
 }
 
 // Config for model selector.
 type Config struct {
 	OpenRouterAPIKey string
-	EvaluatorAPIKey  string
-	EvaluatorModel   string
+	Evaluator        EvaluatorLLMConfig
 	DefaultModel     string
 	ScheduleHour     int // Hour of day (0-23) for daily evaluation
+
+	// Benchmark selects by translating a reference sample with each candidate
+	// (see BenchmarkEvaluator) instead of judging model metadata.
+	Benchmark      bool
+	MaxCandidates  int
+	ScoreboardPath string
+
+	// Languages the models are evaluated for (default: English → Chinese),
+	// and an optional filter on candidate model IDs for the target language.
+	SourceLanguage string
+	TargetLanguage string
+	Filter         CandidateFilter
+
+	// Store persists the selection across restarts. A restored selection
+	// younger than ReuseWithin is used as-is instead of evaluating at startup.
+	Store       StateStore
+	ReuseWithin time.Duration
+
+	// DemoteAfter is how many consecutive failed or rate-limited jobs demote
+	// the selected model (default: 3).
+	DemoteAfter int
+}
+
+// defaultReuseWithin matches the scheduler's once-per-day evaluation.
+const defaultReuseWithin = 23 * time.Hour
+
+// fingerprint identifies the settings that influence which model is chosen;
+// a saved selection made with different settings is not reused.
+func (c Config) fingerprint() string {
+	return fmt.Sprintf("%s|%s|%v|%s|%s|%d|%v", c.Evaluator.Provider, c.Evaluator.Model, c.Benchmark,
+		c.SourceLanguage, c.TargetLanguage, c.MaxCandidates, c.Filter)
 }
 
 // NewSelector creates a model selector service.
@@ -43,8 +86,9 @@ func NewSelector(cfg Config) (*Selector, error) {
 	if cfg.OpenRouterAPIKey == "" {
 		return nil, fmt.Errorf("openrouter API key required")
 	}
-	if cfg.EvaluatorAPIKey == "" {
-		return nil, fmt.Errorf("evaluator API key required")
+	llm, err := NewLLM(cfg.Evaluator)
+	if err != nil {
+		return nil, fmt.Errorf("evaluator: %w", err)
 	}
 	if cfg.DefaultModel == "" {
 		return nil, fmt.Errorf("default model required")
@@ -54,12 +98,47 @@ func NewSelector(cfg Config) (*Selector, error) {
 	if cfg.ScheduleHour < 0 || cfg.ScheduleHour > 23 {
 		cfg.ScheduleHour = 3
 	}
+	if cfg.ReuseWithin <= 0 {
+		cfg.ReuseWithin = defaultReuseWithin
+	}
+	if cfg.DemoteAfter <= 0 {
+		cfg.DemoteAfter = defaultDemoteAfter
+	}
+	if cfg.SourceLanguage == "" {
+		cfg.SourceLanguage = "English"
+	}
+	if cfg.TargetLanguage == "" {
+		cfg.TargetLanguage = "Chinese"
+	}
+
+	var evaluator Evaluator = NewMetadataEvaluator(llm, cfg.SourceLanguage, cfg.TargetLanguage)
+	if cfg.Benchmark {
+		bench, err := NewBenchmarkEvaluator(BenchmarkConfig{
+			OpenRouterAPIKey: cfg.OpenRouterAPIKey,
+			Judge:            llm,
+			SourceLanguage:   cfg.SourceLanguage,
+			TargetLanguage:   cfg.TargetLanguage,
+			MaxCandidates:    cfg.MaxCandidates,
+			ScoreboardPath:   cfg.ScoreboardPath,
+		})
+		if err != nil {
+			return nil, err
+		}
+		evaluator = bench
+	}
 
 	return &Selector{
 		openRouterClient: openrouter.NewClient(cfg.OpenRouterAPIKey),
-		evaluator:        NewGeminiEvaluator(cfg.EvaluatorAPIKey, cfg.EvaluatorModel),
+		evaluator:        evaluator,
 		defaultModel:     cfg.DefaultModel,
 		scheduleHour:     cfg.ScheduleHour,
+		store:            cfg.Store,
+		reuseWithin:      cfg.ReuseWithin,
+		demoteAfter:      cfg.DemoteAfter,
+		filter:           cfg.Filter,
+		failures:         make(map[string]int),
+		blacklist:        make(map[string]time.Time),
+		fingerprint:      cfg.fingerprint(),
 		stop:             make(chan struct{}),
 	}, nil
 }
@@ -77,14 +156,15 @@ func (s *Selector) Start(ctx context.Context) error {
 	logger.Infof("🕐 Using timezone: %s (UTC%s%d)", zone, offsetSign, offsetHours)
 	logger.Infof("🚀 Starting model selector (daily evaluation at %02d:00 %s)", s.scheduleHour, zone)
 
-	// Initial evaluation (blocking)
-	if err := s.evaluate(); err != nil {
+	// Reuse a recent selection from a previous run, else evaluate (blocking)
+	if s.restore(ctx) {
+		s.mu.RLock()
+		logger.Infof("♻️  Restored model selection: %s (evaluated %s ago), skipping startup evaluation",
+			s.selected, time.Since(s.lastEvalTime).Round(time.Minute))
+		s.mu.RUnlock()
+	} else if err := s.evaluate("startup evaluation"); err != nil {
 		logger.Errorf("❌ Initial model evaluation failed: %v", err)
-		logger.Infof("⚠️  Using configured model as fallback: %s", s.defaultModel)
-		s.mu.Lock()
-		s.selected = s.defaultModel
-		s.lastKnown = s.defaultModel
-		s.mu.Unlock()
+		s.fallBack(err)
 	}
 
 	// Start background scheduler
@@ -93,9 +173,9 @@ func (s *Selector) Start(ctx context.Context) error {
 	return nil
 }
 
-// Stop stops the scheduler.
+// Stop stops the scheduler. Safe to call more than once.
 func (s *Selector) Stop() {
-	close(s.stop)
+	s.stopOnce.Do(func() { close(s.stop) })
 }
 
 // GetCurrentModel returns the currently selected model (thread-safe).
@@ -120,8 +200,127 @@ func (s *Selector) OnModelUpdate(cb ModelUpdateCallback) {
 	s.callbacks = append(s.callbacks, cb)
 }
 
-// evaluate fetches models and selects the best one.
-func (s *Selector) evaluate() error {
+// History returns past selections, oldest first (thread-safe).
+func (s *Selector) History() []Selection {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]Selection(nil), s.history...)
+}
+
+// restore loads the saved state. It reports whether the saved selection is
+// recent enough, and made with the same settings, to skip evaluation. The
+// last-known-good model is restored either way.
+func (s *Selector) restore(ctx context.Context) bool {
+	if s.store == nil {
+		return false
+	}
+
+	state, err := s.store.Load(ctx)
+	if err != nil {
+		logger.Warnf("⚠️  Could not restore model selection: %v", err)
+		return false
+	}
+	if state == nil {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastKnown = state.LastKnown
+	s.history = state.History
+	s.evalFailures = state.EvalFailures
+	s.ranking = state.Ranking
+	for m, n := range state.Failures {
+		s.failures[m] = n
+	}
+	for m, until := range state.Blacklist {
+		if time.Now().Before(until) {
+			s.blacklist[m] = until
+		}
+	}
+
+	if state.Selected == "" || state.Fingerprint != s.fingerprint || time.Since(state.LastEvalTime) >= s.reuseWithin {
+		return false
+	}
+	s.selected = state.Selected
+	s.lastEvalTime = state.LastEvalTime
+	return true
+}
+
+// persist saves the current state if a store is configured.
+func (s *Selector) persist() {
+	if s.store == nil {
+		return
+	}
+
+	s.mu.RLock()
+	state := &State{
+		Selected:     s.selected,
+		LastKnown:    s.lastKnown,
+		LastEvalTime: s.lastEvalTime,
+		EvalFailures: s.evalFailures,
+		Fingerprint:  s.fingerprint,
+		History:      append([]Selection(nil), s.history...),
+		Ranking:      append([]string(nil), s.ranking...),
+		Failures:     make(map[string]int, len(s.failures)),
+		Blacklist:    make(map[string]time.Time, len(s.blacklist)),
+	}
+	for m, n := range s.failures {
+		state.Failures[m] = n
+	}
+	for m, until := range s.blacklist {
+		state.Blacklist[m] = until
+	}
+	s.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.store.Save(ctx, state); err != nil {
+		logger.Warnf("⚠️  Could not persist model selection: %v", err)
+	}
+}
+
+// recordLocked appends to the selection history. Caller must hold s.mu.
+func (s *Selector) recordLocked(model, reason string) {
+	s.history = append(s.history, Selection{Model: model, At: time.Now().UTC(), Reason: reason})
+	if len(s.history) > maxHistory {
+		s.history = s.history[len(s.history)-maxHistory:]
+	}
+}
+
+// fallBack keeps the last-known-good model after a failed startup
+// evaluation, or the configured default if there is none.
+func (s *Selector) fallBack(cause error) {
+	s.mu.Lock()
+	s.evalFailures++
+	model := s.lastKnown
+	if model == "" {
+		model = s.defaultModel
+	}
+	s.selected = model
+	s.lastKnown = model
+	s.recordLocked(model, fmt.Sprintf("fallback: %v", cause))
+	s.mu.Unlock()
+
+	logger.Infof("⚠️  Using fallback model: %s", model)
+	s.persist()
+}
+
+// evaluate fetches models and selects the best one. reason is recorded in the
+// selection history.
+func (s *Selector) evaluate(reason string) error {
+	err := s.runEvaluation(reason)
+	if err != nil {
+		s.mu.Lock()
+		s.evalFailures++
+		s.mu.Unlock()
+		s.persist()
+	}
+	return err
+}
+
+func (s *Selector) runEvaluation(reason string) error {
 	logger.Infof("🔍 Fetching free models from OpenRouter...")
 
 	models, err := s.openRouterClient.GetFreeModels()
@@ -129,6 +328,7 @@ func (s *Selector) evaluate() error {
 		return fmt.Errorf("fetch models: %w", err)
 	}
 
+	models = s.withoutBlacklisted(s.filter.apply(models))
 	if len(models) == 0 {
 		return fmt.Errorf("no free models available")
 	}
@@ -139,15 +339,24 @@ func (s *Selector) evaluate() error {
 	if err != nil {
 		return fmt.Errorf("select model: %w", err)
 	}
+	ranking := rankingFor(s.evaluator, selected, models)
 
 	s.mu.Lock()
 	oldModel := s.selected
 	s.selected = selected
 	s.lastKnown = selected
 	s.lastEvalTime = time.Now()
+	s.evalFailures = 0
+	s.ranking = ranking
+	s.failures[selected] = 0
+	if oldModel != selected {
+		s.recordLocked(selected, reason)
+	}
 	callbacks := s.callbacks
 	s.mu.Unlock()
 
+	s.persist()
+
 	// Notify callbacks if model changed
 	if oldModel != selected && oldModel != "" {
 		logger.Infof("🔄 Model changed: %s → %s", oldModel, selected)
@@ -180,7 +389,7 @@ func (s *Selector) runScheduler() {
 
 				if time.Since(lastEval) >= 23*time.Hour {
 					logger.Infof("⏰ Daily evaluation triggered")
-					if err := s.evaluate(); err != nil {
+					if err := s.evaluate("daily evaluation"); err != nil {
 						logger.Errorf("❌ Scheduled evaluation failed: %v", err)
 						logger.Infof("⚠️  Continuing with last known good model")
 					}
@@ -189,3 +398,40 @@ func (s *Selector) runScheduler() {
 		}
 	}
 }
+
+// CandidateFilter narrows the free models considered for a target language by
+// case-insensitive substrings of the model ID, e.g. Include ["qwen"] for
+// Chinese or Exclude ["mistral"] for Japanese.
+type CandidateFilter struct {
+	Include []string // If set, a model must match at least one entry
+	Exclude []string // A model matching any entry is dropped
+}
+
+func (f CandidateFilter) apply(models []openrouter.Model) []openrouter.Model {
+	if len(f.Include) == 0 && len(f.Exclude) == 0 {
+		return models
+	}
+
+	kept := make([]openrouter.Model, 0, len(models))
+	for _, m := range models {
+		id := strings.ToLower(m.ID)
+		if len(f.Include) > 0 && !containsAny(id, f.Include) {
+			continue
+		}
+		if containsAny(id, f.Exclude) {
+			continue
+		}
+		kept = append(kept, m)
+	}
+	logger.Infof("🔎 Candidate filter kept %d/%d models (include: %v, exclude: %v)", len(kept), len(models), f.Include, f.Exclude)
+	return kept
+}
+
+func containsAny(s string, subs []string) bool {
+	for _, sub := range subs {
+		if sub != "" && strings.Contains(s, strings.ToLower(sub)) {
+			return true
+		}
+	}
+	return false
+}
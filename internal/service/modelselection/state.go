@@ -0,0 +1,80 @@
+package modelselection
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// maxHistory caps how many past selections are kept in the persisted state.
+const maxHistory = 50
+
+// State is the part of a Selector that survives restarts.
+type State struct {
+	Selected     string      `json:"selected"`
+	LastKnown    string      `json:"last_known"`
+	LastEvalTime time.Time   `json:"last_eval_time"`
+	EvalFailures int         `json:"eval_failures"` // Consecutive failed evaluations
+	Fingerprint  string      `json:"fingerprint"`   // Evaluation settings the selection was made with
+	History      []Selection `json:"history"`       // Oldest first
+
+	Ranking   []string             `json:"ranking"`   // Candidates of the last evaluation, best first
+	Failures  map[string]int       `json:"failures"`  // Consecutive job failures per model
+	Blacklist map[string]time.Time `json:"blacklist"` // Demoted models and when they may be used again
+}
+
+// Selection records one change of the selected model.
+type Selection struct {
+	Model  string    `json:"model"`
+	At     time.Time `json:"at"`
+	Reason string    `json:"reason"`
+}
+
+// StateStore persists selector state between runs.
+type StateStore interface {
+	// Load returns the saved state, or nil if there is none.
+	Load(ctx context.Context) (*State, error)
+	Save(ctx context.Context, state *State) error
+}
+
+// RedisStateStore keeps the selector state as JSON under a single Redis key.
+type RedisStateStore struct {
+	redis *redis.Client
+	key   string
+}
+
+// NewRedisStateStore creates a Redis-backed state store.
+func NewRedisStateStore(client *redis.Client, key string) *RedisStateStore {
+	return &RedisStateStore{redis: client, key: key}
+}
+
+func (r *RedisStateStore) Load(ctx context.Context) (*State, error) {
+	data, err := r.redis.Get(ctx, r.key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load selector state: %w", err)
+	}
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("decode selector state: %w", err)
+	}
+	return &state, nil
+}
+
+func (r *RedisStateStore) Save(ctx context.Context, state *State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("encode selector state: %w", err)
+	}
+	if err := r.redis.Set(ctx, r.key, data, 0).Err(); err != nil {
+		return fmt.Errorf("save selector state: %w", err)
+	}
+	return nil
+}
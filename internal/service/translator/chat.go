@@ -0,0 +1,226 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fusionn-subs/internal/client/openai"
+	"github.com/fusionn-subs/internal/client/openrouter"
+	"github.com/fusionn-subs/internal/config"
+	"github.com/fusionn-subs/internal/types"
+	"github.com/fusionn-subs/pkg/logger"
+)
+
+// ChatTranslator translates subtitles natively through an OpenAI-compatible
+// chat completions API instead of shelling out to llm-subtrans.
+type ChatTranslator struct {
+	provider string // "openrouter" or "local_llm"
+	label    string // Human-readable provider name for logs
+	limiter  *rateLimiter
+
+	mu       sync.RWMutex
+	client   *openai.Client
+	settings chatSettings
+}
+
+// chatSettings is the provider-specific part of a ChatTranslator's config.
+type chatSettings struct {
+	baseURL      string
+	endpoint     string
+	apiKey       string
+	model        string
+	instruction  string
+	rateLimit    int
+	maxBatchSize int
+	timeout      time.Duration
+}
+
+func openRouterChatSettings(cfg config.OpenRouterConfig) chatSettings {
+	rateLimit := cfg.RateLimit
+	if rateLimit == 0 {
+		rateLimit = 10
+	}
+	return chatSettings{
+		baseURL:      openrouter.DefaultBaseURL,
+		endpoint:     "/chat/completions",
+		apiKey:       cfg.APIKey,
+		model:        cfg.Model,
+		instruction:  cfg.Instruction,
+		rateLimit:    rateLimit,
+		maxBatchSize: cfg.MaxBatchSize,
+		timeout:      config.DefaultGeminiTimeout,
+	}
+}
+
+func localLLMChatSettings(cfg config.LocalLLMConfig) chatSettings {
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = "/v1/chat/completions"
+	}
+	rateLimit := cfg.RateLimit
+	if rateLimit == 0 {
+		rateLimit = 10
+	}
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = config.DefaultLocalLLMTimeout
+	}
+	return chatSettings{
+		baseURL:      cfg.BaseURL,
+		endpoint:     endpoint,
+		apiKey:       cfg.APIKey,
+		model:        cfg.Model,
+		instruction:  cfg.Instruction,
+		rateLimit:    rateLimit,
+		maxBatchSize: cfg.MaxBatchSize,
+		timeout:      timeout,
+	}
+}
+
+// NewOpenRouterChatTranslator creates a native OpenRouter translator.
+func NewOpenRouterChatTranslator(cfg config.OpenRouterConfig) *ChatTranslator {
+	return newChatTranslator("openrouter", "OpenRouter", openRouterChatSettings(cfg))
+}
+
+// NewLocalLLMChatTranslator creates a native translator for a local
+// OpenAI-compatible server.
+func NewLocalLLMChatTranslator(cfg config.LocalLLMConfig) *ChatTranslator {
+	return newChatTranslator("local_llm", "Local LLM", localLLMChatSettings(cfg))
+}
+
+func newChatTranslator(provider, label string, s chatSettings) *ChatTranslator {
+	t := &ChatTranslator{
+		provider: provider,
+		label:    label,
+		limiter:  newRateLimiter(s.rateLimit),
+	}
+	t.apply(s)
+	return t
+}
+
+// apply swaps in new settings. Caller must hold t.mu for writing, or be the constructor.
+func (t *ChatTranslator) apply(s chatSettings) {
+	t.settings = s
+	// The HTTP timeout bounds a single batch; the job timeout bounds the whole file.
+	t.client = openai.NewClient(s.baseURL, s.endpoint, s.apiKey, min(s.timeout, 10*time.Minute))
+	t.limiter.setRate(s.rateLimit)
+}
+
+// UpdateModel updates the model used for translation (thread-safe).
+func (t *ChatTranslator) UpdateModel(newModel string) {
+	t.mu.Lock()
+	oldModel := t.settings.model
+	t.settings.model = newModel
+	t.mu.Unlock()
+
+	if oldModel != newModel {
+		logger.Infof("🔄 Translator model updated: %s → %s", oldModel, newModel)
+	}
+}
+
+// GetModel returns the current model (thread-safe).
+func (t *ChatTranslator) GetModel() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.settings.model
+}
+
+// Models returns the model the next job would use.
+func (t *ChatTranslator) Models() []ModelRef {
+	return []ModelRef{{Provider: t.provider, Model: t.GetModel()}}
+}
+
+// UpdateFromConfig reloads provider settings from the full config (hot-reload).
+func (t *ChatTranslator) UpdateFromConfig(cfg *config.Config) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var s chatSettings
+	switch t.provider {
+	case "openrouter":
+		s = openRouterChatSettings(cfg.OpenRouter)
+		if cfg.OpenRouter.AutoSelectModel {
+			// Keep the auto-selected model; cfg.Model is only the fallback
+			s.model = t.settings.model
+		}
+	case "local_llm":
+		s = localLLMChatSettings(cfg.LocalLLM)
+	default:
+		return
+	}
+	t.apply(s)
+
+	logger.Infof("🔄 %s native config reloaded: %s (model: %s)", t.label, s.baseURL, s.model)
+}
+
+// Translate translates an SRT, ASS or WebVTT subtitle batch by batch and
+// writes the result next to the source in the same format.
+func (t *ChatTranslator) Translate(ctx context.Context, msg types.JobMessage, target types.Target) (Result, error) {
+	if err := msg.Validate(); err != nil {
+		return Result{}, fmt.Errorf("invalid message: %w", err)
+	}
+
+	t.mu.RLock()
+	client := t.client
+	s := t.settings
+	t.mu.RUnlock()
+
+	s.model = msg.ModelFor(t.provider, s.model)
+	s.instruction = msg.InstructionOr(s.instruction)
+
+	outputPath := msg.OutputPath(target.OutputSuffix())
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	logger.Infof("🔄 Starting translation (%s native/%s): %s → %s", t.label, s.model, msg.SubtitlePath, outputPath)
+
+	req := batchRequest{
+		TargetLanguage: target.Language,
+		MediaTitle:     strings.TrimSpace(msg.MediaTitle),
+		Instruction:    s.instruction,
+		BatchSize:      s.maxBatchSize,
+	}
+	err := translateFile(ctxTimeout, req, msg, outputPath, t.limiter, func(ctx context.Context, system, user string) (string, error) {
+		return client.Complete(ctx, openai.ChatRequest{
+			Model: s.model,
+			Messages: []openai.Message{
+				{Role: "system", Content: system},
+				{Role: "user", Content: user},
+			},
+			Temperature: 0.3,
+		})
+	})
+	if err != nil {
+		return Result{}, &ProviderError{Provider: t.provider, Model: s.model, Err: mapChatError(err)}
+	}
+
+	logger.Infof("✅ Translation completed: %s", outputPath)
+	return Result{Path: outputPath, Provider: t.provider, Model: s.model}, nil
+}
+
+// mapChatError converts HTTP status codes into the translator sentinels:
+// 402 (no credits) and daily free-tier 429s exhaust the model, other 429s are
+// transient rate limits.
+func mapChatError(err error) error {
+	var apiErr *openai.APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	switch apiErr.StatusCode {
+	case 402:
+		return fmt.Errorf("%w: %w", ErrAllModelsExhausted, err)
+	case 429:
+		lower := strings.ToLower(apiErr.Message)
+		if strings.Contains(lower, "per-day") || strings.Contains(lower, "per day") || strings.Contains(lower, "quota") {
+			return fmt.Errorf("%w: %w", ErrAllModelsExhausted, err)
+		}
+		return fmt.Errorf("%w: %w", ErrRateLimited, err)
+	}
+	return err
+}
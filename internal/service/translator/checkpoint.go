@@ -0,0 +1,103 @@
+package translator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/fusionn-subs/pkg/logger"
+)
+
+// checkpoint records translated cues of an in-progress job in a sidecar file
+// next to the output, so retries, fallback providers and restarts resume from
+// the last completed batch. Cues are stored individually, so providers with
+// different batch sizes can pick up each other's progress.
+type checkpoint struct {
+	JobID          string            `json:"job_id"`
+	SourceHash     string            `json:"source_hash"`
+	TargetLanguage string            `json:"target_language"`
+	Cues           map[string]string `json:"cues"` // 1-based cue number → translated text
+	UpdatedAt      time.Time         `json:"updated_at"`
+
+	path string
+}
+
+// checkpointPath returns the hidden sidecar path for outputPath.
+func checkpointPath(outputPath string) string {
+	dir, base := filepath.Split(outputPath)
+	return filepath.Join(dir, "."+base+".progress.json")
+}
+
+// loadCheckpoint returns the saved progress for this job, or an empty
+// checkpoint if none exists or it belongs to another job or source.
+func loadCheckpoint(outputPath, jobID, sourceHash, targetLanguage string) *checkpoint {
+	cp := &checkpoint{
+		JobID:          jobID,
+		SourceHash:     sourceHash,
+		TargetLanguage: targetLanguage,
+		Cues:           map[string]string{},
+		path:           checkpointPath(outputPath),
+	}
+
+	data, err := os.ReadFile(cp.path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logger.Warnf("Ignoring unreadable checkpoint %s: %v", cp.path, err)
+		}
+		return cp
+	}
+
+	var saved checkpoint
+	if err := json.Unmarshal(data, &saved); err != nil {
+		logger.Warnf("Ignoring corrupt checkpoint %s: %v", cp.path, err)
+		return cp
+	}
+	if saved.JobID != jobID || saved.SourceHash != sourceHash || saved.TargetLanguage != targetLanguage {
+		logger.Infof("Discarding stale checkpoint %s (different job or source)", cp.path)
+		return cp
+	}
+
+	if len(saved.Cues) > 0 {
+		cp.Cues = saved.Cues
+		logger.Infof("⏩ Resuming from checkpoint: %d cues already translated", len(cp.Cues))
+	}
+	return cp
+}
+
+func (c *checkpoint) get(cueNumber int) (string, bool) {
+	text, ok := c.Cues[strconv.Itoa(cueNumber)]
+	return text, ok
+}
+
+// record stores translated cues and persists the checkpoint atomically.
+func (c *checkpoint) record(translated map[int]string) error {
+	for n, text := range translated {
+		c.Cues[strconv.Itoa(n)] = text
+	}
+	c.UpdatedAt = time.Now()
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("encode checkpoint: %w", err)
+	}
+
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// remove deletes the checkpoint once the output has been written.
+func (c *checkpoint) remove() {
+	if err := os.Remove(c.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		logger.Warnf("Failed to remove checkpoint %s: %v", c.path, err)
+	}
+}
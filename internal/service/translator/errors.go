@@ -1,8 +1,27 @@
 package translator
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrRateLimited        = errors.New("model rate limited")
 	ErrAllModelsExhausted = errors.New("all models exhausted for today")
 )
+
+// ProviderError annotates a translation failure with the provider and model
+// that produced it, so callers can record where a job failed.
+type ProviderError struct {
+	Provider string
+	Model    string
+	Err      error
+}
+
+func (e *ProviderError) Error() string {
+	return fmt.Sprintf("%s/%s: %v", e.Provider, e.Model, e.Err)
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
@@ -10,14 +10,63 @@ import (
 	"github.com/fusionn-subs/pkg/logger"
 )
 
+// Translator translates a job's subtitle into one target language and writes
+// it to msg.OutputPath(target.OutputSuffix()).
 type Translator interface {
-	Translate(ctx context.Context, msg types.JobMessage) (string, error)
+	Translate(ctx context.Context, msg types.JobMessage, target types.Target) (Result, error)
+}
+
+// Result describes a finished translation and which model produced it.
+type Result struct {
+	Path     string // Translated subtitle file
+	Provider string // e.g. "gemini", "openrouter", "local_llm"
+	Model    string
+}
+
+// ModelRef identifies a provider/model pair.
+type ModelRef struct {
+	Provider string
+	Model    string
+}
+
+// ModelLister is implemented by translators that can report which models they
+// would currently use, in order of preference.
+type ModelLister interface {
+	Models() []ModelRef
 }
 
 type ConfigUpdater interface {
 	UpdateFromConfig(cfg *config.Config)
 }
 
+// ModelUpdater is implemented by translators whose model can be switched at
+// runtime, e.g. by the OpenRouter auto model selector.
+type ModelUpdater interface {
+	UpdateModel(model string)
+	GetModel() string
+}
+
+// FindModelUpdater returns the translator serving provider, looking through
+// fallback chains and concurrency limits.
+func FindModelUpdater(t Translator, provider string) (ModelUpdater, bool) {
+	switch v := t.(type) {
+	case *FallbackTranslator:
+		for _, nt := range v.translators {
+			if nt.name == provider {
+				return FindModelUpdater(nt.translator, provider)
+			}
+		}
+		return nil, false
+	case *limitedTranslator:
+		if v.name != provider {
+			return nil, false
+		}
+		return FindModelUpdater(v.inner, provider)
+	}
+	u, ok := t.(ModelUpdater)
+	return u, ok
+}
+
 type namedTranslator struct {
 	name       string
 	translator Translator
@@ -27,15 +76,15 @@ type FallbackTranslator struct {
 	translators []namedTranslator
 }
 
-func (f *FallbackTranslator) Translate(ctx context.Context, msg types.JobMessage) (string, error) {
+func (f *FallbackTranslator) Translate(ctx context.Context, msg types.JobMessage, target types.Target) (Result, error) {
 	var lastErr error
-	for _, nt := range f.translators {
-		out, err := nt.translator.Translate(ctx, msg)
+	for _, nt := range f.ordered(msg.Provider) {
+		out, err := nt.translator.Translate(ctx, msg, target)
 		if err == nil {
 			return out, nil
 		}
 		if errors.Is(err, ErrRateLimited) {
-			return "", err
+			return Result{}, err
 		}
 		if errors.Is(err, ErrAllModelsExhausted) {
 			logger.Warnf("translator provider %s: all models exhausted, trying next provider", nt.name)
@@ -46,9 +95,45 @@ func (f *FallbackTranslator) Translate(ctx context.Context, msg types.JobMessage
 		lastErr = err
 	}
 	if lastErr != nil {
-		return "", fmt.Errorf("all providers failed, last error: %w", lastErr)
+		return Result{}, fmt.Errorf("all providers failed, last error: %w", lastErr)
+	}
+	return Result{}, fmt.Errorf("all providers failed")
+}
+
+// ordered returns the fallback chain with the preferred provider moved to the
+// front. An unknown preference is ignored.
+func (f *FallbackTranslator) ordered(preferred string) []namedTranslator {
+	if preferred == "" || f.translators[0].name == preferred {
+		return f.translators
+	}
+
+	list := make([]namedTranslator, 0, len(f.translators))
+	for _, nt := range f.translators {
+		if nt.name == preferred {
+			list = append(list, nt)
+		}
+	}
+	if len(list) == 0 {
+		logger.Warnf("Requested provider %s is not configured, using default order", preferred)
+		return f.translators
+	}
+	for _, nt := range f.translators {
+		if nt.name != preferred {
+			list = append(list, nt)
+		}
 	}
-	return "", fmt.Errorf("all providers failed")
+	return list
+}
+
+// Models lists the models of every provider in fallback order.
+func (f *FallbackTranslator) Models() []ModelRef {
+	var refs []ModelRef
+	for _, nt := range f.translators {
+		if l, ok := nt.translator.(ModelLister); ok {
+			refs = append(refs, l.Models()...)
+		}
+	}
+	return refs
 }
 
 func (f *FallbackTranslator) UpdateFromConfig(cfg *config.Config) {
@@ -60,24 +145,25 @@ func (f *FallbackTranslator) UpdateFromConfig(cfg *config.Config) {
 }
 
 func NewTranslator(ctx context.Context, cfg *config.Config) (Translator, error) {
-	targetLang := cfg.Translator.TargetLanguage
-	outputSuffix := cfg.Translator.OutputSuffix
-
 	if len(cfg.Translator.Providers) > 0 {
 		list := make([]namedTranslator, 0, len(cfg.Translator.Providers))
 		for _, p := range cfg.Translator.Providers {
 			var t Translator
+			var concurrency int
 			switch p {
 			case "gemini":
-				t = NewGeminiTranslator(ctx, cfg.Gemini, targetLang, outputSuffix)
+				t = NewGeminiTranslator(ctx, cfg.Gemini)
+				concurrency = cfg.Gemini.Concurrency
 			case "openrouter":
-				t = NewOpenRouterTranslator(cfg.OpenRouter, targetLang, outputSuffix)
+				t = newOpenRouterProvider(cfg.OpenRouter)
+				concurrency = cfg.OpenRouter.Concurrency
 			case "local_llm":
-				t = NewLocalLLMTranslator(cfg.LocalLLM, targetLang, outputSuffix)
+				t = newLocalLLMProvider(cfg.LocalLLM)
+				concurrency = cfg.LocalLLM.Concurrency
 			default:
 				return nil, fmt.Errorf("unknown translator provider: %q", p)
 			}
-			list = append(list, namedTranslator{name: p, translator: t})
+			list = append(list, namedTranslator{name: p, translator: newLimitedTranslator(p, concurrency, t)})
 		}
 		logger.Infof("🤖 Using providers: %v", cfg.Translator.Providers)
 		if len(list) == 1 {
@@ -89,7 +175,8 @@ func NewTranslator(ctx context.Context, cfg *config.Config) (Translator, error)
 	if cfg.Gemini.APIKey != "" {
 		logger.Infof("🤖 Using Gemini translator (primary: %s, secondary: %s)",
 			cfg.Gemini.PrimaryModel.Name, cfg.Gemini.SecondaryModel.Name)
-		return NewGeminiTranslator(ctx, cfg.Gemini, targetLang, outputSuffix), nil
+		return newLimitedTranslator("gemini", cfg.Gemini.Concurrency,
+			NewGeminiTranslator(ctx, cfg.Gemini)), nil
 	}
 
 	if cfg.OpenRouter.APIKey != "" {
@@ -98,8 +185,27 @@ func NewTranslator(ctx context.Context, cfg *config.Config) (Translator, error)
 		} else {
 			logger.Infof("🤖 Using OpenRouter translator (model: %s)", cfg.OpenRouter.Model)
 		}
-		return NewOpenRouterTranslator(cfg.OpenRouter, targetLang, outputSuffix), nil
+		return newLimitedTranslator("openrouter", cfg.OpenRouter.Concurrency,
+			newOpenRouterProvider(cfg.OpenRouter)), nil
 	}
 
 	return nil, fmt.Errorf("no translator configured: gemini.api_key is required")
 }
+
+// newOpenRouterProvider picks the script or native engine for OpenRouter.
+func newOpenRouterProvider(cfg config.OpenRouterConfig) Translator {
+	if cfg.Engine == config.EngineNative {
+		logger.Infof("🤖 OpenRouter: native engine (model: %s)", cfg.Model)
+		return NewOpenRouterChatTranslator(cfg)
+	}
+	return NewOpenRouterTranslator(cfg)
+}
+
+// newLocalLLMProvider picks the script or native engine for a local server.
+func newLocalLLMProvider(cfg config.LocalLLMConfig) Translator {
+	if cfg.Engine == config.EngineNative {
+		logger.Infof("🤖 Local LLM: native engine (%s, model: %s)", cfg.BaseURL, cfg.Model)
+		return NewLocalLLMChatTranslator(cfg)
+	}
+	return NewLocalLLMTranslator(cfg)
+}
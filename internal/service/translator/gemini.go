@@ -2,6 +2,7 @@ package translator
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -10,11 +11,15 @@ import (
 	"sync"
 	"time"
 
+	"github.com/fusionn-subs/internal/client/gemini"
 	"github.com/fusionn-subs/internal/config"
 	"github.com/fusionn-subs/internal/types"
 	"github.com/fusionn-subs/pkg/logger"
 )
 
+// geminiBatchTimeout bounds a single native generateContent call.
+const geminiBatchTimeout = 5 * time.Minute
+
 var pacificTZ *time.Location
 
 func init() {
@@ -26,21 +31,22 @@ func init() {
 }
 
 type GeminiTranslator struct {
-	scriptPath     string
-	workDir        string
-	apiKey         string
-	instruction    string
-	targetLanguage string
-	outputSuffix   string
+	scriptPath string
+	workDir    string
+	limiter    *rateLimiter
 
 	mu               sync.RWMutex
+	apiKey           string
+	instruction      string
+	engine           string
+	client           *gemini.Client
 	primaryModel     config.GeminiModelConfig
 	secondaryModel   config.GeminiModelConfig
 	activeModel      *config.GeminiModelConfig
 	primaryExhausted bool
 }
 
-func NewGeminiTranslator(ctx context.Context, cfg config.GeminiConfig, targetLang, outputSuffix string) *GeminiTranslator {
+func NewGeminiTranslator(ctx context.Context, cfg config.GeminiConfig) *GeminiTranslator {
 	scriptPath := os.Getenv("GEMINI_SCRIPT_PATH")
 	if scriptPath == "" {
 		scriptPath = "/opt/llm-subtrans/gemini-subtrans.sh"
@@ -55,88 +61,199 @@ func NewGeminiTranslator(ctx context.Context, cfg config.GeminiConfig, targetLan
 		workDir:        workDir,
 		apiKey:         cfg.APIKey,
 		instruction:    cfg.Instruction,
-		targetLanguage: targetLang,
-		outputSuffix:   outputSuffix,
+		engine:         cfg.Engine,
+		client:         gemini.NewClient(cfg.APIKey, geminiBatchTimeout),
+		limiter:        newRateLimiter(cfg.PrimaryModel.RateLimit),
 		primaryModel:   cfg.PrimaryModel,
 		secondaryModel: cfg.SecondaryModel,
 	}
 	t.activeModel = &t.primaryModel
 
 	logger.Infof("🤖 Gemini translator: primary=%s, secondary=%s", cfg.PrimaryModel.Name, cfg.SecondaryModel.Name)
+	if cfg.Engine == config.EngineNative {
+		logger.Infof("🤖 Gemini: native engine (generateContent REST API)")
+	}
 
 	t.startDailyReset(ctx)
 
 	return t
 }
 
-func (t *GeminiTranslator) Translate(ctx context.Context, msg types.JobMessage) (string, error) {
+// geminiJob is a snapshot of translator settings taken at the start of a job.
+type geminiJob struct {
+	apiKey      string
+	instruction string
+	engine      string
+	client      *gemini.Client
+	model       config.GeminiModelConfig
+	isPrimary   bool
+	requested   bool // Model was requested by the job, not picked by primary/secondary fallback
+	language    string
+	outputPath  string
+}
+
+func (t *GeminiTranslator) Translate(ctx context.Context, msg types.JobMessage, target types.Target) (Result, error) {
 	if err := msg.Validate(); err != nil {
-		return "", fmt.Errorf("invalid message: %w", err)
+		return Result{}, fmt.Errorf("invalid message: %w", err)
 	}
 
-	outputPath := msg.OutputPath(t.outputSuffix)
-
 	t.mu.RLock()
-	model := *t.activeModel
-	isPrimary := !t.primaryExhausted
+	job := geminiJob{
+		apiKey:      t.apiKey,
+		instruction: msg.InstructionOr(t.instruction),
+		engine:      t.engine,
+		client:      t.client,
+		model:       *t.activeModel,
+		isPrimary:   !t.primaryExhausted,
+		language:    target.Language,
+		outputPath:  msg.OutputPath(target.OutputSuffix()),
+	}
 	t.mu.RUnlock()
 
+	if name := msg.ModelFor("gemini", ""); name != "" {
+		job.model.Name = name
+		job.requested = true
+	}
+
 	ctxTimeout, cancel := context.WithTimeout(ctx, config.DefaultGeminiTimeout)
 	defer cancel()
 
+	var resultPath string
+	var rateLimited bool
+	var err error
+	if job.engine == config.EngineNative {
+		resultPath, err = t.translateNative(ctxTimeout, msg, job)
+		var apiErr *gemini.APIError
+		rateLimited = errors.As(err, &apiErr) && apiErr.IsResourceExhausted()
+	} else {
+		var combinedOutput string
+		resultPath, combinedOutput, err = t.translateScript(ctxTimeout, msg, job)
+		rateLimited = err != nil && isRateLimitError(combinedOutput)
+	}
+
+	if err != nil {
+		os.Remove(job.outputPath)
+
+		if rateLimited {
+			if job.requested {
+				// Leave the shared primary/secondary state alone for a one-off model
+				err = fmt.Errorf("%w: requested model %s exhausted: %w", ErrAllModelsExhausted, job.model.Name, err)
+			} else if job.isPrimary {
+				t.switchToSecondary()
+				err = fmt.Errorf("%w: %s exhausted, switched to %s: %w", ErrRateLimited, job.model.Name, t.secondaryModelName(), err)
+			} else {
+				err = fmt.Errorf("%w: %s also exhausted: %w", ErrAllModelsExhausted, job.model.Name, err)
+			}
+		}
+
+		return Result{}, &ProviderError{Provider: "gemini", Model: job.model.Name, Err: err}
+	}
+
+	return Result{Path: resultPath, Provider: "gemini", Model: job.model.Name}, nil
+}
+
+// translateScript runs gemini-subtrans.sh and returns its combined output for
+// rate-limit detection.
+func (t *GeminiTranslator) translateScript(ctx context.Context, msg types.JobMessage, job geminiJob) (resultPath, combinedOutput string, err error) {
 	args := []string{
 		msg.SubtitlePath,
-		"-o", outputPath,
-		"-l", t.targetLanguage,
-		"-k", t.apiKey,
+		"-o", job.outputPath,
+		"-l", job.language,
+		"-k", job.apiKey,
 	}
 
-	if model.Name != "" {
-		args = append(args, "-m", model.Name)
+	if job.model.Name != "" {
+		args = append(args, "-m", job.model.Name)
 	}
 
 	if mediaTitle := strings.TrimSpace(msg.MediaTitle); mediaTitle != "" {
 		args = append(args, "--moviename", mediaTitle)
 	}
 
-	if t.instruction != "" {
-		args = append(args, "--instruction", t.instruction)
+	if job.instruction != "" {
+		args = append(args, "--instruction", job.instruction)
 	}
 
-	if model.RateLimit > 0 {
-		args = append(args, "--ratelimit", strconv.Itoa(model.RateLimit))
+	if job.model.RateLimit > 0 {
+		args = append(args, "--ratelimit", strconv.Itoa(job.model.RateLimit))
 	}
 
-	if model.MaxBatchSize > 0 {
-		args = append(args, "--maxbatchsize", strconv.Itoa(model.MaxBatchSize))
+	if job.model.MaxBatchSize > 0 {
+		args = append(args, "--maxbatchsize", strconv.Itoa(job.model.MaxBatchSize))
 	}
 
-	cmd := exec.CommandContext(ctxTimeout, t.scriptPath, args...)
+	cmd := exec.CommandContext(ctx, t.scriptPath, args...)
 	if t.workDir != "" {
 		cmd.Dir = t.workDir
 	}
 
-	cmd.Env = append(os.Environ(), "GEMINI_API_KEY="+t.apiKey, "PYTHONUNBUFFERED=1")
+	cmd.Env = append(os.Environ(), "GEMINI_API_KEY="+job.apiKey, "PYTHONUNBUFFERED=1")
 
-	logger.Infof("🔄 Starting translation (Gemini/%s): %s → %s", model.Name, msg.SubtitlePath, outputPath)
+	logger.Infof("🔄 Starting translation (Gemini/%s): %s → %s", job.model.Name, msg.SubtitlePath, job.outputPath)
 	logger.Debugf("Command: %s", maskAPIKeyInCommand(buildCommandLine(t.scriptPath, args)))
 
-	resultPath, combinedOutput, err := executeScript(cmd, outputPath)
-	if err != nil {
-		os.Remove(outputPath)
-
-		if isRateLimitError(combinedOutput) {
-			if isPrimary {
-				t.switchToSecondary()
-				return "", fmt.Errorf("%w: %s exhausted, switched to %s", ErrRateLimited, model.Name, t.secondaryModel.Name)
-			}
-			return "", fmt.Errorf("%w: %s also exhausted", ErrAllModelsExhausted, model.Name)
-		}
+	return executeScript(cmd, job.outputPath)
+}
 
+// translateNative sends batched cues to generateContent with a JSON response
+// schema and writes the translated SRT, ASS or WebVTT file.
+func (t *GeminiTranslator) translateNative(ctx context.Context, msg types.JobMessage, job geminiJob) (string, error) {
+	logger.Infof("🔄 Starting translation (Gemini native/%s): %s → %s", job.model.Name, msg.SubtitlePath, job.outputPath)
+
+	t.limiter.setRate(job.model.RateLimit)
+	req := batchRequest{
+		TargetLanguage: job.language,
+		MediaTitle:     strings.TrimSpace(msg.MediaTitle),
+		Instruction:    job.instruction,
+		BatchSize:      job.model.MaxBatchSize,
+	}
+	err := translateFile(ctx, req, msg, job.outputPath, t.limiter, func(ctx context.Context, system, user string) (string, error) {
+		return job.client.GenerateContent(ctx, job.model.Name, gemini.Request{
+			SystemInstruction: system,
+			Prompt:            user,
+			Temperature:       0.3,
+			ResponseSchema:    batchResponseSchema,
+		})
+	})
+	if err != nil {
 		return "", err
 	}
 
-	return resultPath, nil
+	logger.Infof("✅ Translation completed: %s", job.outputPath)
+	return job.outputPath, nil
+}
+
+// batchResponseSchema constrains Gemini output to the batch reply format.
+var batchResponseSchema = map[string]any{
+	"type": "OBJECT",
+	"properties": map[string]any{
+		"translations": map[string]any{
+			"type": "ARRAY",
+			"items": map[string]any{
+				"type": "OBJECT",
+				"properties": map[string]any{
+					"id":   map[string]any{"type": "INTEGER"},
+					"text": map[string]any{"type": "STRING"},
+				},
+				"required": []string{"id", "text"},
+			},
+		},
+	},
+	"required": []string{"translations"},
+}
+
+// Models returns the active model; the secondary only takes over once the
+// primary is exhausted.
+func (t *GeminiTranslator) Models() []ModelRef {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return []ModelRef{{Provider: "gemini", Model: t.activeModel.Name}}
+}
+
+func (t *GeminiTranslator) secondaryModelName() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.secondaryModel.Name
 }
 
 func (t *GeminiTranslator) switchToSecondary() {
@@ -165,8 +282,12 @@ func (t *GeminiTranslator) UpdateFromConfig(cfg *config.Config) {
 	geminiCfg := cfg.Gemini
 	wasPrimaryExhausted := t.primaryExhausted
 
+	if geminiCfg.APIKey != t.apiKey {
+		t.client = gemini.NewClient(geminiCfg.APIKey, geminiBatchTimeout)
+	}
 	t.apiKey = geminiCfg.APIKey
 	t.instruction = geminiCfg.Instruction
+	t.engine = geminiCfg.Engine
 	t.primaryModel = geminiCfg.PrimaryModel
 	t.secondaryModel = geminiCfg.SecondaryModel
 
@@ -0,0 +1,157 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/fusionn-subs/internal/config"
+	"github.com/fusionn-subs/internal/types"
+	"github.com/fusionn-subs/pkg/logger"
+)
+
+// limitedTranslator caps how many jobs a single provider handles at once.
+// Each provider gets its own slots so a slow provider does not block others.
+// When all slots are taken, waiting jobs are admitted by priority, then in
+// arrival order.
+type limitedTranslator struct {
+	name     string
+	limit    int
+	inner    Translator
+	observer atomic.Pointer[OutcomeFunc]
+
+	mu      sync.Mutex
+	running int
+	waiting [3][]chan struct{} // Per priority rank, see priorityRank
+}
+
+// priorityRank orders job priorities for slot hand-off; lower goes first.
+func priorityRank(priority string) int {
+	switch priority {
+	case types.PriorityHigh:
+		return 0
+	case types.PriorityLow:
+		return 2
+	}
+	return 1
+}
+
+// OutcomeFunc receives the model and error of every job a provider failed.
+type OutcomeFunc func(model string, err error)
+
+// Observe registers fn for jobs failed by provider inside t, replacing any
+// earlier observer. It reports false if t does not use provider. Successes
+// are not reported: an output only counts once the caller has validated it,
+// so the caller reports those itself.
+func Observe(t Translator, provider string, fn OutcomeFunc) bool {
+	switch v := t.(type) {
+	case *FallbackTranslator:
+		for _, nt := range v.translators {
+			if nt.name == provider {
+				return Observe(nt.translator, provider, fn)
+			}
+		}
+	case *limitedTranslator:
+		if v.name == provider {
+			v.observer.Store(&fn)
+			return true
+		}
+	}
+	return false
+}
+
+func newLimitedTranslator(name string, concurrency int, inner Translator) *limitedTranslator {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	return &limitedTranslator{
+		name:  name,
+		limit: concurrency,
+		inner: inner,
+	}
+}
+
+// acquire takes a slot, waiting behind jobs of the same or higher priority
+// while the provider is busy.
+func (l *limitedTranslator) acquire(ctx context.Context, msg types.JobMessage) error {
+	rank := priorityRank(msg.Priority)
+
+	l.mu.Lock()
+	if l.running < l.limit {
+		l.running++
+		l.mu.Unlock()
+		return nil
+	}
+	ready := make(chan struct{})
+	l.waiting[rank] = append(l.waiting[rank], ready)
+	running := l.running
+	l.mu.Unlock()
+
+	logger.Infof("⏳ Provider %s busy (%d/%d), waiting: job_id=%s", l.name, running, l.limit, msg.JobID)
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	l.mu.Lock()
+	for i, ch := range l.waiting[rank] {
+		if ch == ready {
+			l.waiting[rank] = append(l.waiting[rank][:i], l.waiting[rank][i+1:]...)
+			l.mu.Unlock()
+			return ctx.Err()
+		}
+	}
+	l.mu.Unlock()
+
+	// The slot was handed over while ctx was canceled; pass it on
+	l.release()
+	return ctx.Err()
+}
+
+// release hands the slot to the next waiting job, or frees it.
+func (l *limitedTranslator) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for rank, queue := range l.waiting {
+		if len(queue) > 0 {
+			close(queue[0])
+			l.waiting[rank] = queue[1:]
+			return
+		}
+	}
+	l.running--
+}
+
+func (l *limitedTranslator) Translate(ctx context.Context, msg types.JobMessage, target types.Target) (Result, error) {
+	if err := l.acquire(ctx, msg); err != nil {
+		return Result{}, err
+	}
+	defer l.release()
+
+	res, err := l.inner.Translate(ctx, msg, target)
+	if fn := l.observer.Load(); fn != nil && err != nil {
+		model := res.Model
+		var providerErr *ProviderError
+		if errors.As(err, &providerErr) {
+			model = providerErr.Model
+		}
+		(*fn)(model, err)
+	}
+	return res, err
+}
+
+func (l *limitedTranslator) Models() []ModelRef {
+	if m, ok := l.inner.(ModelLister); ok {
+		return m.Models()
+	}
+	return nil
+}
+
+func (l *limitedTranslator) UpdateFromConfig(cfg *config.Config) {
+	if u, ok := l.inner.(ConfigUpdater); ok {
+		u.UpdateFromConfig(cfg)
+	}
+}
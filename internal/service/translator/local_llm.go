@@ -17,23 +17,21 @@ import (
 
 // LocalLLMTranslator implements translation using a custom OpenAI-compatible server via llm-subtrans.sh
 type LocalLLMTranslator struct {
-	scriptPath     string
-	workDir        string
-	mu             sync.RWMutex
-	baseURL        string
-	apiKey         string
-	model          string
-	endpoint       string
-	instruction    string
-	rateLimit      int
-	maxBatchSize   int
-	timeout        time.Duration
-	targetLanguage string
-	outputSuffix   string
+	scriptPath   string
+	workDir      string
+	mu           sync.RWMutex
+	baseURL      string
+	apiKey       string
+	model        string
+	endpoint     string
+	instruction  string
+	rateLimit    int
+	maxBatchSize int
+	timeout      time.Duration
 }
 
 // NewLocalLLMTranslator creates a new local LLM (custom server) translator
-func NewLocalLLMTranslator(cfg config.LocalLLMConfig, targetLang, outputSuffix string) *LocalLLMTranslator {
+func NewLocalLLMTranslator(cfg config.LocalLLMConfig) *LocalLLMTranslator {
 	scriptPath := os.Getenv("LLM_SUBTRANS_SCRIPT_PATH")
 	if scriptPath == "" {
 		scriptPath = "/opt/llm-subtrans/llm-subtrans.sh"
@@ -59,39 +57,36 @@ func NewLocalLLMTranslator(cfg config.LocalLLMConfig, targetLang, outputSuffix s
 	}
 
 	return &LocalLLMTranslator{
-		scriptPath:     scriptPath,
-		workDir:        workDir,
-		baseURL:        cfg.BaseURL,
-		apiKey:         cfg.APIKey,
-		model:          cfg.Model,
-		endpoint:       endpoint,
-		instruction:    cfg.Instruction,
-		rateLimit:      rateLimit,
-		maxBatchSize:   cfg.MaxBatchSize,
-		timeout:        timeout,
-		targetLanguage: targetLang,
-		outputSuffix:   outputSuffix,
+		scriptPath:   scriptPath,
+		workDir:      workDir,
+		baseURL:      cfg.BaseURL,
+		apiKey:       cfg.APIKey,
+		model:        cfg.Model,
+		endpoint:     endpoint,
+		instruction:  cfg.Instruction,
+		rateLimit:    rateLimit,
+		maxBatchSize: cfg.MaxBatchSize,
+		timeout:      timeout,
 	}
 }
 
 // Translate translates subtitles using a local/custom OpenAI-compatible endpoint
-func (t *LocalLLMTranslator) Translate(ctx context.Context, msg types.JobMessage) (string, error) {
+func (t *LocalLLMTranslator) Translate(ctx context.Context, msg types.JobMessage, target types.Target) (Result, error) {
 	if err := msg.Validate(); err != nil {
-		return "", fmt.Errorf("invalid message: %w", err)
+		return Result{}, fmt.Errorf("invalid message: %w", err)
 	}
 
-	outputPath := msg.OutputPath(t.outputSuffix)
+	outputPath := msg.OutputPath(target.OutputSuffix())
 
 	t.mu.RLock()
 	baseURL := t.baseURL
 	apiKey := t.apiKey
-	model := t.model
+	model := msg.ModelFor("local_llm", t.model)
 	endpoint := t.endpoint
-	instruction := t.instruction
+	instruction := msg.InstructionOr(t.instruction)
 	rateLimit := t.rateLimit
 	maxBatchSize := t.maxBatchSize
 	timeout := t.timeout
-	targetLanguage := t.targetLanguage
 	t.mu.RUnlock()
 
 	ctxTimeout, cancel := context.WithTimeout(ctx, timeout)
@@ -100,7 +95,7 @@ func (t *LocalLLMTranslator) Translate(ctx context.Context, msg types.JobMessage
 	args := []string{
 		msg.SubtitlePath,
 		"-o", outputPath,
-		"-l", targetLanguage,
+		"-l", target.Language,
 		"-s", baseURL,
 		"-e", endpoint,
 	}
@@ -146,10 +141,17 @@ func (t *LocalLLMTranslator) Translate(ctx context.Context, msg types.JobMessage
 	resultPath, _, err := executeScript(cmd, outputPath)
 	if err != nil {
 		os.Remove(outputPath)
-		return "", err
+		return Result{}, &ProviderError{Provider: "local_llm", Model: model, Err: err}
 	}
 
-	return resultPath, nil
+	return Result{Path: resultPath, Provider: "local_llm", Model: model}, nil
+}
+
+// Models returns the configured model.
+func (t *LocalLLMTranslator) Models() []ModelRef {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return []ModelRef{{Provider: "local_llm", Model: t.model}}
 }
 
 // UpdateFromConfig reloads translator settings from the full config (hot-reload)
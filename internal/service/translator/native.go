@@ -0,0 +1,273 @@
+package translator
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/fusionn-subs/internal/subtitle"
+	"github.com/fusionn-subs/internal/types"
+	"github.com/fusionn-subs/pkg/logger"
+)
+
+//go:embed prompts/batch_system.tmpl
+var batchSystemPromptTemplate string
+
+var batchSystemPrompt = template.Must(template.New("batch_system").Parse(batchSystemPromptTemplate))
+
+const (
+	defaultNativeBatchSize = 20
+	maxBatchAttempts       = 2 // Re-ask once when a reply cannot be parsed
+)
+
+// completeFunc sends one system/user prompt pair to a model and returns the
+// raw reply. Provider-specific errors are mapped by the caller.
+type completeFunc func(ctx context.Context, system, user string) (string, error)
+
+// batchRequest describes how a native engine should translate one subtitle.
+type batchRequest struct {
+	TargetLanguage string
+	MediaTitle     string
+	Instruction    string
+	BatchSize      int
+}
+
+type batchLine struct {
+	ID   int    `json:"id"`
+	Text string `json:"text"`
+}
+
+// errMalformedReply marks a reply that could not be matched to the batch.
+var errMalformedReply = errors.New("malformed model reply")
+
+// translateFile translates the SRT, ASS or WebVTT subtitle at msg.SubtitlePath into
+// outputPath, checkpointing after every batch so an interrupted job can resume.
+func translateFile(ctx context.Context, req batchRequest, msg types.JobMessage, outputPath string, limiter *rateLimiter, complete completeFunc) error {
+	if !subtitle.IsSupported(msg.SubtitlePath) {
+		return fmt.Errorf("native engine supports SRT, ASS and WebVTT input only: %s", msg.SubtitlePath)
+	}
+
+	sub, err := subtitle.ReadFile(msg.SubtitlePath)
+	if err != nil {
+		return fmt.Errorf("native engine: %w", err)
+	}
+
+	cp := loadCheckpoint(outputPath, msg.JobID, sub.Hash(), req.TargetLanguage)
+
+	out, err := translateCues(ctx, req, sub, cp, limiter, complete)
+	if err != nil {
+		return err
+	}
+
+	if err := subtitle.WriteFile(outputPath, out); err != nil {
+		return err
+	}
+
+	cp.remove()
+	return nil
+}
+
+// translateCues translates all non-empty cues of sub in batches and returns a
+// copy with translated text and unchanged timing. Cues already present in cp
+// are reused; each completed batch is recorded in cp.
+func translateCues(ctx context.Context, req batchRequest, sub *subtitle.Subtitle, cp *checkpoint, limiter *rateLimiter, complete completeFunc) (*subtitle.Subtitle, error) {
+	var system strings.Builder
+	if err := batchSystemPrompt.Execute(&system, req); err != nil {
+		return nil, fmt.Errorf("build system prompt: %w", err)
+	}
+
+	batchSize := req.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultNativeBatchSize
+	}
+
+	out := sub.Clone()
+
+	pending := make([]int, 0, len(sub.Cues))
+	for i, c := range sub.Cues {
+		if c.IsEmpty() {
+			continue
+		}
+		if text, ok := cp.get(i + 1); ok {
+			out.Cues[i].Lines = strings.Split(text, "\n")
+			continue
+		}
+		pending = append(pending, i)
+	}
+
+	batches := (len(pending) + batchSize - 1) / batchSize
+	for b := 0; b < batches; b++ {
+		indexes := pending[b*batchSize : min((b+1)*batchSize, len(pending))]
+		lines := make([]batchLine, len(indexes))
+		for i, idx := range indexes {
+			lines[i] = batchLine{ID: idx + 1, Text: sub.Cues[idx].Text()}
+		}
+
+		logger.Infof("📝 Batch %d/%d (%d lines)", b+1, batches, len(lines))
+
+		translated, err := translateBatch(ctx, system.String(), lines, limiter, complete)
+		if err != nil {
+			return nil, fmt.Errorf("batch %d/%d: %w", b+1, batches, err)
+		}
+
+		for _, idx := range indexes {
+			out.Cues[idx].Lines = strings.Split(translated[idx+1], "\n")
+		}
+
+		if err := cp.record(translated); err != nil {
+			logger.Warnf("Checkpoint not saved: %v", err)
+		}
+	}
+
+	return out, nil
+}
+
+// BatchPrompt renders the native engine prompts for translating every cue of
+// sub in a single batch. Model benchmarking uses it so candidates are tested
+// with exactly what production sends; the reply format is described in the
+// system prompt.
+func BatchPrompt(targetLanguage string, sub *subtitle.Subtitle) (system, user string, err error) {
+	var sb strings.Builder
+	if err := batchSystemPrompt.Execute(&sb, batchRequest{TargetLanguage: targetLanguage}); err != nil {
+		return "", "", fmt.Errorf("build system prompt: %w", err)
+	}
+
+	lines := make([]batchLine, 0, len(sub.Cues))
+	for i, c := range sub.Cues {
+		if !c.IsEmpty() {
+			lines = append(lines, batchLine{ID: i + 1, Text: c.Text()})
+		}
+	}
+	user, err = encodeBatch(lines)
+	if err != nil {
+		return "", "", err
+	}
+	return sb.String(), user, nil
+}
+
+func encodeBatch(lines []batchLine) (string, error) {
+	payload, err := json.Marshal(struct {
+		Lines []batchLine `json:"lines"`
+	}{Lines: lines})
+	if err != nil {
+		return "", fmt.Errorf("encode batch: %w", err)
+	}
+	return string(payload), nil
+}
+
+func translateBatch(ctx context.Context, system string, lines []batchLine, limiter *rateLimiter, complete completeFunc) (map[int]string, error) {
+	payload, err := encodeBatch(lines)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= maxBatchAttempts; attempt++ {
+		if err := limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		reply, err := complete(ctx, system, payload)
+		if err != nil {
+			return nil, err
+		}
+
+		translated, err := parseBatchReply(reply, lines)
+		if err == nil {
+			return translated, nil
+		}
+
+		lastErr = err
+		logger.Warnf("Batch reply rejected (attempt %d/%d): %v", attempt, maxBatchAttempts, err)
+	}
+
+	return nil, lastErr
+}
+
+// parseBatchReply extracts the JSON object from a model reply, tolerating
+// markdown fences and surrounding prose, and checks every id was translated.
+func parseBatchReply(reply string, lines []batchLine) (map[int]string, error) {
+	start := strings.Index(reply, "{")
+	end := strings.LastIndex(reply, "}")
+	if start == -1 || end < start {
+		return nil, fmt.Errorf("%w: no JSON object in reply", errMalformedReply)
+	}
+
+	var parsed struct {
+		Translations []batchLine `json:"translations"`
+	}
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &parsed); err != nil {
+		return nil, fmt.Errorf("%w: %w", errMalformedReply, err)
+	}
+
+	byID := make(map[int]string, len(parsed.Translations))
+	for _, t := range parsed.Translations {
+		byID[t.ID] = strings.TrimSpace(t.Text)
+	}
+
+	// Only keep requested ids; models occasionally invent extra entries
+	translated := make(map[int]string, len(lines))
+	for _, l := range lines {
+		if byID[l.ID] == "" {
+			return nil, fmt.Errorf("%w: missing translation for line %d", errMalformedReply, l.ID)
+		}
+		translated[l.ID] = byID[l.ID]
+	}
+
+	return translated, nil
+}
+
+// rateLimiter spaces requests evenly to stay under a requests-per-minute
+// budget. It is shared by all jobs using the same translator.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newRateLimiter(rpm int) *rateLimiter {
+	r := &rateLimiter{}
+	r.setRate(rpm)
+	return r
+}
+
+func (r *rateLimiter) setRate(rpm int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if rpm <= 0 {
+		r.interval = 0
+		return
+	}
+	r.interval = time.Minute / time.Duration(rpm)
+}
+
+func (r *rateLimiter) wait(ctx context.Context) error {
+	r.mu.Lock()
+	now := time.Now()
+	at := r.next
+	if at.Before(now) {
+		at = now
+	}
+	r.next = at.Add(r.interval)
+	r.mu.Unlock()
+
+	delay := time.Until(at)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -16,20 +16,18 @@ import (
 
 // OpenRouterTranslator implements translation using OpenRouter API via llm-subtrans.sh
 type OpenRouterTranslator struct {
-	scriptPath     string
-	workDir        string
-	apiKey         string
-	mu             sync.RWMutex // Protects model field
-	model          string
-	instruction    string
-	maxBatchSize   int
-	rateLimit      int
-	targetLanguage string
-	outputSuffix   string
+	scriptPath   string
+	workDir      string
+	apiKey       string
+	mu           sync.RWMutex // Protects model field
+	model        string
+	instruction  string
+	maxBatchSize int
+	rateLimit    int
 }
 
 // NewOpenRouterTranslator creates a new OpenRouter translator
-func NewOpenRouterTranslator(cfg config.OpenRouterConfig, targetLang, outputSuffix string) *OpenRouterTranslator {
+func NewOpenRouterTranslator(cfg config.OpenRouterConfig) *OpenRouterTranslator {
 	scriptPath := os.Getenv("LLM_SUBTRANS_SCRIPT_PATH")
 	if scriptPath == "" {
 		scriptPath = "/opt/llm-subtrans/llm-subtrans.sh"
@@ -46,15 +44,13 @@ func NewOpenRouterTranslator(cfg config.OpenRouterConfig, targetLang, outputSuff
 	}
 
 	return &OpenRouterTranslator{
-		scriptPath:     scriptPath,
-		workDir:        workDir,
-		apiKey:         cfg.APIKey,
-		model:          cfg.Model,
-		instruction:    cfg.Instruction,
-		maxBatchSize:   cfg.MaxBatchSize,
-		rateLimit:      rateLimit,
-		targetLanguage: targetLang,
-		outputSuffix:   outputSuffix,
+		scriptPath:   scriptPath,
+		workDir:      workDir,
+		apiKey:       cfg.APIKey,
+		model:        cfg.Model,
+		instruction:  cfg.Instruction,
+		maxBatchSize: cfg.MaxBatchSize,
+		rateLimit:    rateLimit,
 	}
 }
 
@@ -77,27 +73,33 @@ func (t *OpenRouterTranslator) GetModel() string {
 	return t.model
 }
 
+// Models returns the model the next job would use.
+func (t *OpenRouterTranslator) Models() []ModelRef {
+	return []ModelRef{{Provider: "openrouter", Model: t.GetModel()}}
+}
+
 // Translate translates subtitles using OpenRouter
-func (t *OpenRouterTranslator) Translate(ctx context.Context, msg types.JobMessage) (string, error) {
+func (t *OpenRouterTranslator) Translate(ctx context.Context, msg types.JobMessage, target types.Target) (Result, error) {
 	if err := msg.Validate(); err != nil {
-		return "", fmt.Errorf("invalid message: %w", err)
+		return Result{}, fmt.Errorf("invalid message: %w", err)
 	}
 
-	outputPath := msg.OutputPath(t.outputSuffix)
+	outputPath := msg.OutputPath(target.OutputSuffix())
 
 	ctxTimeout, cancel := context.WithTimeout(ctx, config.DefaultGeminiTimeout)
 	defer cancel()
 
-	// Get current model (thread-safe)
+	// Get current model (thread-safe), unless the job asks for its own
 	t.mu.RLock()
-	currentModel := t.model
+	currentModel := msg.ModelFor("openrouter", t.model)
 	t.mu.RUnlock()
+	instruction := msg.InstructionOr(t.instruction)
 
 	// Build args for llm-subtrans.sh (OpenRouter default)
 	args := []string{
 		msg.SubtitlePath,
 		"-o", outputPath,
-		"-l", t.targetLanguage,
+		"-l", target.Language,
 		"--apikey", t.apiKey,
 		"--model", currentModel,
 	}
@@ -106,8 +108,8 @@ func (t *OpenRouterTranslator) Translate(ctx context.Context, msg types.JobMessa
 		args = append(args, "--moviename", mediaTitle)
 	}
 
-	if t.instruction != "" {
-		args = append(args, "--instruction", t.instruction)
+	if instruction != "" {
+		args = append(args, "--instruction", instruction)
 	}
 
 	if t.rateLimit > 0 {
@@ -131,5 +133,8 @@ func (t *OpenRouterTranslator) Translate(ctx context.Context, msg types.JobMessa
 	logger.Debugf("Command: %s", maskAPIKeyInCommand(buildCommandLine(t.scriptPath, args)))
 
 	resultPath, _, err := executeScript(cmd, outputPath)
-	return resultPath, err
+	if err != nil {
+		return Result{}, &ProviderError{Provider: "openrouter", Model: currentModel, Err: err}
+	}
+	return Result{Path: resultPath, Provider: "openrouter", Model: currentModel}, nil
 }
@@ -0,0 +1,95 @@
+package worker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fusionn-subs/internal/config"
+	"github.com/fusionn-subs/internal/subtitle"
+	"github.com/fusionn-subs/internal/types"
+	"github.com/fusionn-subs/pkg/logger"
+)
+
+const defaultBilingualSuffix = "bi"
+
+// targetFiles are the files a job delivers for one target.
+type targetFiles struct {
+	Path      string // Main output: the translation, or the bilingual file in "only" mode
+	Bilingual string // Bilingual file, if one was written
+}
+
+// bilingualLayout identifies the bilingual settings in idempotency records so
+// files written with other settings are not reused. It is empty when no
+// bilingual file is written; only natively parsed sources are combined.
+func (w *Worker) bilingualLayout(source *subtitle.Subtitle) string {
+	b := w.cfg.Bilingual
+	if !b.Enabled() || source == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s/%s/%s/%v/%v", b.Mode, b.Format, b.Order, b.Suffix, b.SourceStyle, b.TranslationStyle)
+}
+
+// expectedFiles returns where a target's files are delivered.
+func (w *Worker) expectedFiles(msg types.JobMessage, source *subtitle.Subtitle, target types.Target) targetFiles {
+	translation := w.translationPath(msg, target)
+	if w.bilingualLayout(source) == "" {
+		return targetFiles{Path: translation}
+	}
+
+	b := w.cfg.Bilingual
+	ext := ".srt"
+	switch b.Format {
+	case config.BilingualFormatASS:
+		ext = ".ass"
+	case config.BilingualFormatVTT:
+		ext = ".vtt"
+	}
+	base := strings.TrimSuffix(translation, filepath.Ext(translation))
+
+	if b.Mode == config.BilingualOnly {
+		return targetFiles{Path: base + ext, Bilingual: base + ext}
+	}
+	suffix := b.Suffix
+	if suffix == "" {
+		suffix = defaultBilingualSuffix
+	}
+	bilingual := base + "." + strings.TrimPrefix(suffix, ".") + ext
+	return targetFiles{Path: translation, Bilingual: bilingual}
+}
+
+// writeBilingual combines source with the translation at translationPath
+// into files.Bilingual. In "only" mode the translation-only file is removed
+// unless the bilingual file replaced it.
+func (w *Worker) writeBilingual(source *subtitle.Subtitle, translationPath string, files targetFiles, target types.Target) error {
+	out, err := subtitle.ReadFile(translationPath)
+	if err != nil {
+		return err
+	}
+
+	b := w.cfg.Bilingual
+	bi, err := subtitle.Bilingual(source, out, subtitle.BilingualOptions{
+		SourceFirst:      b.Order == config.BilingualSourceFirst,
+		SourceStyle:      b.SourceStyle,
+		TranslationStyle: b.TranslationStyle,
+		VTT:              b.Format == config.BilingualFormatVTT,
+	})
+	if err != nil {
+		return err
+	}
+
+	bi.Font = w.fontFor(target)
+	if err := subtitle.WriteFile(files.Bilingual, bi); err != nil {
+		return err
+	}
+
+	if b.Mode == config.BilingualOnly && filepath.Clean(translationPath) != filepath.Clean(files.Bilingual) {
+		if err := os.Remove(translationPath); err != nil {
+			logger.Warnf("Failed to remove translation-only output %s: %v", translationPath, err)
+		}
+	}
+
+	logger.Infof("🈯 Bilingual subtitle written: %s", files.Bilingual)
+	return nil
+}
@@ -0,0 +1,196 @@
+package worker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/fusionn-subs/internal/service/translator"
+	"github.com/fusionn-subs/internal/subtitle"
+	"github.com/fusionn-subs/internal/types"
+	"github.com/fusionn-subs/pkg/logger"
+)
+
+// jobInput is what a job translates: the parsed source (nil for formats that
+// are not parsed natively), the file handed to the translator, the content
+// hash and encoding of the job's original subtitle and the cleanup rules
+// applied to it.
+type jobInput struct {
+	source   *subtitle.Subtitle
+	path     string // msg.SubtitlePath, or a UTF-8 or cleaned copy of it
+	hash     string
+	encoding subtitle.Encoding
+	cleanup  string // Hash of the effective cleanup rules; empty when none apply
+}
+
+// cleanupProvider returns the provider whose cleanup rules apply to msg: the
+// one the job asked for if it is configured, otherwise the first in the chain.
+// Fallback providers translate the same cleaned input.
+func (w *Worker) cleanupProvider(msg types.JobMessage) string {
+	if slices.Contains(w.cfg.Providers, msg.Provider) {
+		return msg.Provider
+	}
+	if len(w.cfg.Providers) > 0 {
+		return w.cfg.Providers[0]
+	}
+	return ""
+}
+
+// cleanSource applies the cleanup rules to in.source. When anything changed,
+// the cleaned subtitle is written to the job's staging directory and becomes
+// the translator's input; the returned function removes it once the job is
+// done.
+func (w *Worker) cleanSource(msg types.JobMessage, in jobInput) (jobInput, func(), error) {
+	noop := func() {}
+	if in.source == nil {
+		return in, noop, nil
+	}
+	rules := w.cfg.Cleanup.For(w.cleanupProvider(msg))
+	if !rules.Enabled() {
+		return in, noop, nil
+	}
+	in.cleanup = rulesHash(rules)
+
+	cleaned, stats, err := subtitle.Cleanup(in.source, rules)
+	if err != nil {
+		return in, noop, err
+	}
+	if !stats.Changed() {
+		logger.Debugf("Cleanup changed nothing: job_id=%s", msg.JobID)
+		return in, noop, nil
+	}
+	if err := cleaned.Validate(); err != nil {
+		return in, noop, fmt.Errorf("nothing left to translate after cleanup: %w", err)
+	}
+
+	path, remove, err := stageInput(msg, "cleaned", func(path string) error {
+		return subtitle.WriteFile(path, cleaned)
+	})
+	if err != nil {
+		return in, noop, fmt.Errorf("write cleaned subtitle: %w", err)
+	}
+
+	logger.Infof("🧹 Cleanup: %s (%d → %d cues): job_id=%s", stats, len(in.source.Cues), len(cleaned.Cues), msg.JobID)
+	in.source, in.path = cleaned, path
+	return in, remove, nil
+}
+
+// rulesHash identifies cleanup rules in idempotency records, so changing them
+// translates a source again.
+func rulesHash(rules subtitle.CleanupRules) string {
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// stageInput writes a replacement for the job's subtitle into inputDir, under
+// the original file name so the translator's output is named as usual. The
+// returned function removes the directory. The job ID is checked here too, as
+// the directory is removed recursively.
+func stageInput(msg types.JobMessage, kind string, write func(path string) error) (string, func(), error) {
+	if !types.IsSafeID(msg.JobID) {
+		return "", nil, fmt.Errorf("job_id %q cannot name a staging dir", msg.JobID)
+	}
+	dir := inputDir(msg.JobID, kind)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", nil, fmt.Errorf("create staging dir: %w", err)
+	}
+	remove := func() {
+		if err := os.RemoveAll(dir); err != nil {
+			logger.Warnf("Failed to remove staging dir %s: %v", dir, err)
+		}
+	}
+
+	path := filepath.Join(dir, filepath.Base(msg.SubtitlePath))
+	if err := write(path); err != nil {
+		return "", nil, err
+	}
+	return path, remove, nil
+}
+
+// inputDir is where a job's rewritten subtitle of the given kind is staged.
+// Like stageDir it is fixed per job, so the translator's checkpoints next to
+// the staged file survive retries, restarts and dead-letter replays.
+func inputDir(jobID, kind string) string {
+	return filepath.Join(stagingRoot(), jobID, "input-"+kind)
+}
+
+// stagingRoot holds one directory per job with staged input and stageDir.
+func stagingRoot() string {
+	return filepath.Join(os.TempDir(), "fusionn-subs")
+}
+
+// sweepStaging removes job directories not modified within maxAge. A job that
+// failed for good keeps its staged input for a dead-letter replay; once its
+// idempotency records have expired as well, nothing resumes from it.
+func sweepStaging(maxAge time.Duration) {
+	entries, err := os.ReadDir(stagingRoot())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warnf("Failed to read staging root: %v", err)
+		}
+		return
+	}
+
+	removed := 0
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || !e.IsDir() || time.Since(info.ModTime()) < maxAge {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(stagingRoot(), e.Name())); err != nil {
+			logger.Warnf("Failed to remove staging dir %s: %v", e.Name(), err)
+			continue
+		}
+		removed++
+	}
+	if removed > 0 {
+		logger.Infof("🧹 Removed %d stale staging dir(s)", removed)
+	}
+}
+
+// runTranslator translates the file at in.path. A cleaned input is translated
+// next to its staged copy, so the result is moved to where the job's output
+// belongs.
+func (w *Worker) runTranslator(ctx context.Context, msg types.JobMessage, in jobInput, target types.Target) (translator.Result, error) {
+	if in.path == "" || in.path == msg.SubtitlePath {
+		return w.translator.Translate(ctx, msg, target)
+	}
+
+	staged := msg
+	staged.SubtitlePath = in.path
+	res, err := w.translator.Translate(ctx, staged, target)
+	if err != nil {
+		return res, err
+	}
+
+	dst := filepath.Join(filepath.Dir(msg.SubtitlePath), filepath.Base(res.Path))
+	if err := moveFile(res.Path, dst); err != nil {
+		return res, fmt.Errorf("move output of cleaned source: %w", err)
+	}
+	res.Path = dst
+	return res, nil
+}
+
+// moveFile renames src to dst, copying when they are on different devices.
+func moveFile(src, dst string) error {
+	err := os.Rename(src, dst)
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	if err := copyFile(src, dst); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
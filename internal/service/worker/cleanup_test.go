@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fusionn-subs/internal/types"
+)
+
+func TestStageInputRejectsUnsafeJobID(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	for _, id := range []string{"", "..", "../outside", "a/b"} {
+		msg := types.JobMessage{JobID: id, SubtitlePath: "/media/show.en.srt"}
+		wrote := false
+		_, _, err := stageInput(msg, "cleaned", func(string) error {
+			wrote = true
+			return nil
+		})
+		if err == nil || wrote {
+			t.Errorf("stageInput(job_id=%q) staged the input", id)
+		}
+	}
+
+	msg := types.JobMessage{JobID: "job-1_a", SubtitlePath: "/media/show.en.srt"}
+	path, remove, err := stageInput(msg, "cleaned", func(path string) error {
+		return os.WriteFile(path, []byte("x"), 0o644)
+	})
+	if err != nil {
+		t.Fatalf("stageInput: %v", err)
+	}
+	if want := filepath.Join(inputDir("job-1_a", "cleaned"), "show.en.srt"); path != want {
+		t.Errorf("path = %q, want %q", path, want)
+	}
+	remove()
+	if _, err := os.Stat(filepath.Dir(path)); !os.IsNotExist(err) {
+		t.Errorf("staging dir left behind: %v", err)
+	}
+}
+
+func TestSweepStaging(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	old := time.Now().Add(-48 * time.Hour)
+	for _, id := range []string{"old-job", "new-job"} {
+		if err := os.MkdirAll(inputDir(id, "cleaned"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Chtimes(filepath.Join(stagingRoot(), "old-job"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	sweepStaging(24 * time.Hour)
+
+	if _, err := os.Stat(filepath.Join(stagingRoot(), "old-job")); !os.IsNotExist(err) {
+		t.Errorf("stale job dir kept: %v", err)
+	}
+	if _, err := os.Stat(inputDir("new-job", "cleaned")); err != nil {
+		t.Errorf("recent job dir removed: %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/fusionn-subs/internal/service/translator"
+	"github.com/fusionn-subs/internal/types"
+	"github.com/fusionn-subs/pkg/logger"
+)
+
+// jobFailure records why and after how much effort a job was given up on.
+type jobFailure struct {
+	attempts  int
+	startedAt time.Time
+	err       error
+}
+
+func (f *jobFailure) Error() string { return f.err.Error() }
+func (f *jobFailure) Unwrap() error { return f.err }
+
+// deadLetterMetaKey holds the failure metadata for a dead-lettered job.
+func deadLetterMetaKey(deadLetterQueue, jobID string) string {
+	return deadLetterQueue + ":meta:" + jobID
+}
+
+// deadLetter moves a failed job from the processing list to the dead-letter
+// list and stores its failure metadata, all in one transaction. The original
+// payload is kept verbatim so it can be replayed with LMOVE/LPUSH later.
+func (w *Worker) deadLetter(ctx context.Context, rawMsg string, msg types.JobMessage, failure *jobFailure) {
+	ctx = context.WithoutCancel(ctx)
+
+	meta := map[string]any{
+		"job_id":      msg.JobID,
+		"error":       failure.err.Error(),
+		"attempts":    strconv.Itoa(failure.attempts),
+		"provider":    "",
+		"model":       "",
+		"worker_id":   w.cfg.WorkerID,
+		"queue":       w.cfg.Queue,
+		"received_at": failure.startedAt.UTC().Format(time.RFC3339),
+		"failed_at":   time.Now().UTC().Format(time.RFC3339),
+		"payload":     rawMsg,
+	}
+
+	var providerErr *translator.ProviderError
+	if errors.As(failure.err, &providerErr) {
+		meta["provider"] = providerErr.Provider
+		meta["model"] = providerErr.Model
+	}
+
+	_, err := w.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.LPush(ctx, w.cfg.DeadLetterQueue, rawMsg)
+		if msg.JobID != "" {
+			pipe.HSet(ctx, deadLetterMetaKey(w.cfg.DeadLetterQueue, msg.JobID), meta)
+		}
+		pipe.LRem(ctx, w.processingKey, 1, rawMsg)
+		return nil
+	})
+	if err != nil {
+		logger.Errorf("Failed to dead-letter job %s: %v", msg.JobID, err)
+		return
+	}
+
+	logger.Warnf("🪦 Job moved to dead-letter queue %s: job_id=%s (attempts: %d)", w.cfg.DeadLetterQueue, msg.JobID, failure.attempts)
+}
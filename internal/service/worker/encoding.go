@@ -0,0 +1,85 @@
+package worker
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fusionn-subs/internal/subtitle"
+	"github.com/fusionn-subs/internal/types"
+	"github.com/fusionn-subs/pkg/logger"
+)
+
+// decodeSource detects the encoding of the job's subtitle. A source that is
+// not UTF-8, or ends in a DOS end-of-file marker, is rewritten as plain UTF-8
+// into the job's staging directory and becomes the translator's input; the
+// returned function removes it once the job is done. Files that cannot be
+// decoded fail with subtitle.ErrUnreadable.
+func (w *Worker) decodeSource(msg types.JobMessage, in jobInput) (jobInput, func(), error) {
+	noop := func() {}
+	data, err := os.ReadFile(msg.SubtitlePath)
+	if err != nil {
+		return in, noop, fmt.Errorf("read subtitle: %w", err)
+	}
+	text, enc, err := subtitle.Decode(data)
+	if err != nil {
+		return in, noop, err
+	}
+	in.encoding = enc
+	if enc.Charset == subtitle.CharsetUTF8 && len(text) == len(data) {
+		return in, noop, nil
+	}
+
+	path, remove, err := stageInput(msg, "decoded", func(path string) error {
+		out, err := subtitle.Encode(text, subtitle.Encoding{Charset: subtitle.CharsetUTF8})
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, out, 0o644)
+	})
+	if err != nil {
+		return in, noop, fmt.Errorf("write UTF-8 subtitle: %w", err)
+	}
+
+	logger.Infof("🔤 Source is %s, translating a UTF-8 copy: job_id=%s", enc, msg.JobID)
+	in.path = path
+	return in, remove, nil
+}
+
+// outputEncoding names the encoding a job's files are delivered in, for
+// idempotency records.
+func (w *Worker) outputEncoding(in jobInput) string {
+	return w.cfg.Encoding.For(in.encoding).String()
+}
+
+// encodeOutput rewrites the delivered files in the configured output
+// encoding and byte order mark policy.
+func (w *Worker) encodeOutput(files targetFiles, source subtitle.Encoding) error {
+	enc := w.cfg.Encoding.For(source)
+	paths := []string{files.Path}
+	if files.Bilingual != "" && files.Bilingual != files.Path {
+		paths = append(paths, files.Bilingual)
+	}
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		text, current, err := subtitle.Decode(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if current == enc {
+			continue
+		}
+		out, err := subtitle.Encode(text, enc)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, out, 0o644); err != nil {
+			return fmt.Errorf("write subtitle: %w", err)
+		}
+		logger.Debugf("Encoded %s as %s (was %s)", path, enc, current)
+	}
+	return nil
+}
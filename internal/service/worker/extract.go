@@ -0,0 +1,52 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fusionn-subs/internal/service/extract"
+	"github.com/fusionn-subs/internal/types"
+	"github.com/fusionn-subs/pkg/logger"
+)
+
+// extractSubtitle extracts the job's embedded subtitle track next to the video
+// and returns its path. A file left by an earlier extraction is reused.
+func (w *Worker) extractSubtitle(ctx context.Context, msg types.JobMessage) (string, error) {
+	streams, err := w.extractor.Streams(ctx, msg.VideoPath)
+	if err != nil {
+		return "", err
+	}
+	stream, err := extract.Select(streams, msg.SubtitleStream)
+	if err != nil {
+		return "", err
+	}
+
+	path := extractedPath(msg.VideoPath, stream)
+	if _, err := os.Stat(path); err == nil {
+		logger.Infof("🎞️  Using extracted subtitle %s: job_id=%s", path, msg.JobID)
+		return path, nil
+	}
+
+	logger.Infof("🎞️  Extracting subtitle stream %d (%s, %s %q): job_id=%s",
+		stream.Index, stream.Codec, stream.Language, stream.Title, msg.JobID)
+	if err := w.extractor.Extract(ctx, msg.VideoPath, stream, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// extractedPath names an extracted track like a sidecar of the video, e.g.
+// "Movie.eng.srt", so outputs are named "Movie.chs.srt".
+func extractedPath(video string, s extract.Stream) string {
+	language := "eng"
+	if !s.IsEnglish() {
+		language = strings.ToLower(s.Language)
+		if language == "" {
+			language = "und"
+		}
+	}
+	return fmt.Sprintf("%s.%s%s", strings.TrimSuffix(video, filepath.Ext(video)), language, s.Ext())
+}
@@ -0,0 +1,84 @@
+package worker
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fusionn-subs/internal/subtitle"
+	"github.com/fusionn-subs/internal/types"
+	"github.com/fusionn-subs/pkg/logger"
+)
+
+// translationPath returns where target's translation is delivered: next to
+// the source, with the extension of the configured output format. Formats
+// that are not parsed natively keep their own.
+func (w *Worker) translationPath(msg types.JobMessage, target types.Target) string {
+	path := msg.OutputPath(target.OutputSuffix())
+	if w.cfg.OutputFormat == "" || !subtitle.IsSupported(path) {
+		return path
+	}
+	return strings.TrimSuffix(path, filepath.Ext(path)) + "." + w.cfg.OutputFormat
+}
+
+// convertOutput rewrites a translation in the configured output format and
+// returns the path of the converted file. The translation in the source
+// format is removed.
+func (w *Worker) convertOutput(path string) (string, error) {
+	if w.cfg.OutputFormat == "" || !subtitle.IsSupported(path) {
+		return path, nil
+	}
+	converted := strings.TrimSuffix(path, filepath.Ext(path)) + "." + w.cfg.OutputFormat
+	if strings.EqualFold(converted, path) {
+		return path, nil
+	}
+
+	sub, err := subtitle.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if err := subtitle.WriteFile(converted, sub); err != nil {
+		return "", err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		logger.Warnf("Failed to remove %s after conversion: %v", path, err)
+	}
+
+	logger.Infof("🔄 Converted to %s: %s", strings.ToUpper(w.cfg.OutputFormat), converted)
+	return converted, nil
+}
+
+// fontFor returns the font of target's translated ASS dialogue, or "" to keep
+// the fonts of the source script.
+func (w *Worker) fontFor(target types.Target) string {
+	if target.Font != "" {
+		return target.Font
+	}
+	return w.cfg.ASSFont
+}
+
+// outputFont returns the font an ASS translation at path is written with, or
+// "" for other formats, which have no font.
+func (w *Worker) outputFont(path string, target types.Target) string {
+	if !subtitle.IsASS(path) {
+		return ""
+	}
+	return w.fontFor(target)
+}
+
+// applyFont sets the target's font on the dialogue styles of an ASS output.
+// Script engines write ASS themselves, so the font is applied afterwards.
+func (w *Worker) applyFont(path string, target types.Target) error {
+	font := w.fontFor(target)
+	if font == "" || !subtitle.IsASS(path) {
+		return nil
+	}
+	sub, err := subtitle.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("set font: %w", err)
+	}
+	sub.Font = font
+	return subtitle.WriteFile(path, sub)
+}
@@ -0,0 +1,279 @@
+package worker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/fusionn-subs/internal/types"
+	"github.com/fusionn-subs/pkg/logger"
+)
+
+const (
+	defaultIdempotencyTTL = 7 * 24 * time.Hour
+
+	// claimTTL bounds how long an in-flight claim survives if it is never
+	// released. Claims of dead workers are taken over before that.
+	claimTTL = 2 * time.Hour
+)
+
+// completion is the idempotency record of a finished translation.
+type completion struct {
+	JobID          string    `json:"job_id"`
+	ContentHash    string    `json:"content_hash"`
+	TargetLanguage string    `json:"target_language"`
+	OutputSuffix   string    `json:"output_suffix"`
+	Provider       string    `json:"provider,omitempty"`    // Provider requested by the job, if any
+	Model          string    `json:"model,omitempty"`       // Model requested by the job, if any
+	Instruction    string    `json:"instruction,omitempty"` // Hash of the job's custom instruction, if any
+	Cleanup        string    `json:"cleanup,omitempty"`     // Hash of the cleanup rules applied to the source, if any
+	OutputPath     string    `json:"output_path"`
+	BilingualPath  string    `json:"bilingual_path,omitempty"`
+	Layout         string    `json:"layout,omitempty"`   // Bilingual settings the files were written with
+	Encoding       string    `json:"encoding,omitempty"` // Character encoding the files were written in
+	Font           string    `json:"font,omitempty"`     // Dialogue font of ASS files
+	CompletedAt    time.Time `json:"completed_at"`
+}
+
+// doneJobKey records the completion of a job ID for one target code.
+func doneJobKey(queue, jobID, code string) string {
+	return fmt.Sprintf("%s:done:job:%s:%s", queue, jobID, code)
+}
+
+// doneHashKey records the completion of a source file by content hash for
+// one target code.
+func doneHashKey(queue, hash, code string) string {
+	return fmt.Sprintf("%s:done:hash:%s:%s", queue, hash, code)
+}
+
+// claimKey marks a job ID as being processed; the value is the worker ID.
+func claimKey(queue, jobID string) string {
+	return fmt.Sprintf("%s:inflight:%s", queue, jobID)
+}
+
+// claim marks a job as in flight. It returns false if another live consumer
+// is already processing the same job ID. A claim left by a dead worker, or by
+// a previous run of this worker, is taken over.
+func (w *Worker) claim(ctx context.Context, jobID string) (bool, error) {
+	key := claimKey(w.cfg.Queue, jobID)
+
+	ok, err := w.redis.SetNX(ctx, key, w.cfg.WorkerID, claimTTL).Result()
+	if err != nil {
+		return false, err
+	}
+	if ok {
+		w.inflight.Store(jobID, struct{}{})
+		return true, nil
+	}
+
+	owner, err := w.redis.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return w.claim(ctx, jobID) // Released in between
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if owner == w.cfg.WorkerID {
+		if w.isLocal(jobID) {
+			return false, nil
+		}
+	} else {
+		alive, err := w.redis.Exists(ctx, heartbeatKey(w.cfg.Queue, owner)).Result()
+		if err != nil {
+			return false, err
+		}
+		if alive > 0 {
+			return false, nil
+		}
+	}
+
+	logger.Infof("♻️  Taking over stale claim from worker %s: job_id=%s", owner, jobID)
+	if err := w.redis.Set(ctx, key, w.cfg.WorkerID, claimTTL).Err(); err != nil {
+		return false, err
+	}
+	w.inflight.Store(jobID, struct{}{})
+	return true, nil
+}
+
+// release drops the in-flight claim once a job is done with.
+func (w *Worker) release(ctx context.Context, jobID string) {
+	w.inflight.Delete(jobID)
+	if err := w.redis.Del(context.WithoutCancel(ctx), claimKey(w.cfg.Queue, jobID)).Err(); err != nil {
+		logger.Warnf("Failed to release claim for job %s: %v", jobID, err)
+	}
+}
+
+func (w *Worker) isLocal(jobID string) bool {
+	_, ok := w.inflight.Load(jobID)
+	return ok
+}
+
+// findCompletion looks for an earlier translation of this job into target,
+// first by job ID and then by source content. Records whose output no longer
+// exists, that were produced for another language, suffix, requested provider,
+// model or instruction, cleanup rules, output format, encoding, ASS font or
+// bilingual layout, or whose source has since changed are ignored.
+func (w *Worker) findCompletion(ctx context.Context, msg types.JobMessage, in jobInput, target types.Target, layout string, files targetFiles) (*completion, string, error) {
+	hash := in.hash
+	keys := []struct{ key, reason string }{
+		{doneJobKey(w.cfg.Queue, msg.JobID, target.Code), "job_id"},
+	}
+	if hash != "" {
+		keys = append(keys, struct{ key, reason string }{doneHashKey(w.cfg.Queue, hash, target.Code), "content hash"})
+	}
+
+	for _, k := range keys {
+		data, err := w.redis.Get(ctx, k.key).Bytes()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, "", err
+		}
+
+		var rec completion
+		if err := json.Unmarshal(data, &rec); err != nil {
+			logger.Warnf("Ignoring malformed idempotency record %s: %v", k.key, err)
+			continue
+		}
+		if rec.TargetLanguage != target.Language || rec.OutputSuffix != target.OutputSuffix() ||
+			rec.Provider != msg.Provider || rec.Model != msg.Model || rec.Instruction != instructionHash(msg.Instruction) ||
+			rec.Cleanup != in.cleanup || rec.Layout != layout || rec.Encoding != w.outputEncoding(in) ||
+			rec.Font != w.outputFont(files.Path, target) ||
+			!strings.EqualFold(filepath.Ext(rec.OutputPath), filepath.Ext(files.Path)) {
+			continue
+		}
+		if hash != "" && rec.ContentHash != "" && rec.ContentHash != hash {
+			logger.Infof("🔁 Source changed since job %s was translated, translating again", rec.JobID)
+			continue
+		}
+		if _, err := os.Stat(rec.OutputPath); err != nil {
+			logger.Infof("🔁 Earlier output for %s is gone, translating again: %s", k.reason, rec.OutputPath)
+			continue
+		}
+		if rec.BilingualPath != "" {
+			if _, err := os.Stat(rec.BilingualPath); err != nil {
+				logger.Infof("🔁 Earlier bilingual output for %s is gone, translating again: %s", k.reason, rec.BilingualPath)
+				continue
+			}
+		}
+		return &rec, k.reason, nil
+	}
+
+	return nil, "", nil
+}
+
+// recordCompletion stores the idempotency records for a job's finished target.
+func (w *Worker) recordCompletion(ctx context.Context, msg types.JobMessage, in jobInput, target types.Target, layout string, files targetFiles) {
+	hash := in.hash
+	data, err := json.Marshal(completion{
+		JobID:          msg.JobID,
+		ContentHash:    hash,
+		TargetLanguage: target.Language,
+		OutputSuffix:   target.OutputSuffix(),
+		Provider:       msg.Provider,
+		Model:          msg.Model,
+		Instruction:    instructionHash(msg.Instruction),
+		Cleanup:        in.cleanup,
+		OutputPath:     files.Path,
+		BilingualPath:  files.Bilingual,
+		Layout:         layout,
+		Encoding:       w.outputEncoding(in),
+		Font:           w.outputFont(files.Path, target),
+		CompletedAt:    time.Now().UTC(),
+	})
+	if err != nil {
+		logger.Warnf("Failed to encode idempotency record: %v", err)
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	_, err = w.redis.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, doneJobKey(w.cfg.Queue, msg.JobID, target.Code), data, w.cfg.IdempotencyTTL)
+		if hash != "" {
+			pipe.Set(ctx, doneHashKey(w.cfg.Queue, hash, target.Code), data, w.cfg.IdempotencyTTL)
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Warnf("Failed to store idempotency record for job %s: %v", msg.JobID, err)
+	}
+}
+
+// reuseOutput makes an earlier output available at this job's output paths.
+// A content match from another job usually points at a different file name
+// (e.g. an upgraded release), so the files are copied next to the new source.
+func (w *Worker) reuseOutput(files targetFiles, rec *completion) error {
+	if filepath.Clean(files.Path) != filepath.Clean(rec.OutputPath) {
+		if err := copyFile(rec.OutputPath, files.Path); err != nil {
+			return fmt.Errorf("copy earlier output: %w", err)
+		}
+	}
+	if files.Bilingual != "" && files.Bilingual != files.Path &&
+		filepath.Clean(files.Bilingual) != filepath.Clean(rec.BilingualPath) {
+		if err := copyFile(rec.BilingualPath, files.Bilingual); err != nil {
+			return fmt.Errorf("copy earlier bilingual output: %w", err)
+		}
+	}
+	return nil
+}
+
+// instructionHash identifies a job's custom instruction in idempotency
+// records without storing it; it is empty when the job has none.
+func instructionHash(instruction string) string {
+	if instruction == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(instruction))
+	return hex.EncodeToString(sum[:8])
+}
+
+// fileHash returns the SHA-256 of a file's contents.
+func fileHash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp := dst + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
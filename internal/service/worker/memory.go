@@ -0,0 +1,285 @@
+package worker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/fusionn-subs/internal/service/translator"
+	"github.com/fusionn-subs/internal/subtitle"
+	"github.com/fusionn-subs/internal/types"
+	"github.com/fusionn-subs/pkg/logger"
+)
+
+// memory is a translation memory with one Redis hash per target language,
+// model and instruction override. Fields are hashes of the source cue text,
+// values the translated text.
+type memory struct {
+	redis  *redis.Client
+	prefix string
+	ttl    time.Duration
+}
+
+// key names the hash for a language and model. Jobs that override the
+// instruction get their own hash, keyed by its instructionHash, as the same
+// line may be translated differently.
+func (m *memory) key(language string, ref translator.ModelRef, instruction string) string {
+	key := m.prefix + ":" + strings.ToLower(language) + ":" + ref.Provider + ":" + ref.Model
+	if instruction != "" {
+		key += ":" + instruction
+	}
+	return key
+}
+
+// memoryField hashes a cue's text with surrounding whitespace trimmed on every
+// line, so re-encoded releases still match while line breaks are preserved.
+func memoryField(c subtitle.Cue) string {
+	lines := make([]string, len(c.Lines))
+	for i, l := range c.Lines {
+		lines[i] = strings.TrimSpace(l)
+	}
+	sum := sha256.Sum256([]byte(strings.Join(lines, "\n")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// lookup returns cached translations by cue position. Models are tried in the
+// given order; the first one that knows a line wins.
+func (m *memory) lookup(ctx context.Context, language string, refs []translator.ModelRef, instruction string, sub *subtitle.Subtitle) (map[int]string, error) {
+	positions := make(map[string][]int)
+	var fields []string
+	for i, c := range sub.Cues {
+		if c.IsEmpty() {
+			continue
+		}
+		f := memoryField(c)
+		if _, seen := positions[f]; !seen {
+			fields = append(fields, f)
+		}
+		positions[f] = append(positions[f], i)
+	}
+	if len(fields) == 0 || len(refs) == 0 {
+		return nil, nil
+	}
+
+	pipe := m.redis.Pipeline()
+	cmds := make([]*redis.SliceCmd, len(refs))
+	for i, ref := range refs {
+		cmds[i] = pipe.HMGet(ctx, m.key(language, ref, instruction), fields...)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	hits := make(map[int]string)
+	for _, cmd := range cmds {
+		for j, v := range cmd.Val() {
+			text, ok := v.(string)
+			if !ok || text == "" {
+				continue
+			}
+			for _, pos := range positions[fields[j]] {
+				if _, done := hits[pos]; !done {
+					hits[pos] = text
+				}
+			}
+		}
+	}
+	return hits, nil
+}
+
+// store records translated cues under the model that produced them. Cues that
+// came back unchanged are skipped so a missed translation is not remembered.
+func (m *memory) store(ctx context.Context, language string, ref translator.ModelRef, instruction string, src, out *subtitle.Subtitle) (int, error) {
+	values := make(map[string]any)
+	for i := range src.Cues {
+		if i >= len(out.Cues) {
+			break
+		}
+		s, o := src.Cues[i], out.Cues[i]
+		if s.IsEmpty() || o.IsEmpty() || s.Text() == o.Text() {
+			continue
+		}
+		values[memoryField(s)] = o.Text()
+	}
+	if len(values) == 0 {
+		return 0, nil
+	}
+
+	key := m.key(language, ref, instruction)
+	_, err := m.redis.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, values)
+		if m.ttl > 0 {
+			pipe.Expire(ctx, key, m.ttl)
+		}
+		return nil
+	})
+	return len(values), err
+}
+
+// translate runs one translation attempt into target and validates its
+// output. With the translation memory enabled, cached cues are filled in first
+// and only the rest is sent to the translator.
+func (w *Worker) translate(ctx context.Context, msg types.JobMessage, in jobInput, target types.Target) (translator.Result, error) {
+	source := in.source
+	if w.memory == nil || source == nil {
+		res, err := w.runTranslator(ctx, msg, in, target)
+		if err != nil {
+			return res, err
+		}
+		err = w.validateOutput(source, res.Path)
+		w.reportOutcome(res, err)
+		return res, err
+	}
+
+	var refs []translator.ModelRef
+	if msg.Model != "" {
+		// Only reuse lines from the model the job asked for
+		refs = []translator.ModelRef{{Provider: msg.Provider, Model: msg.Model}}
+	} else if l, ok := w.translator.(translator.ModelLister); ok {
+		refs = l.Models()
+	}
+	hits, err := w.memory.lookup(ctx, target.Language, refs, instructionHash(msg.Instruction), source)
+	if err != nil {
+		logger.Warnf("Translation memory lookup failed, translating in full: %v", err)
+		hits = nil
+	}
+
+	var res translator.Result
+	if len(hits) == 0 {
+		res, err = w.runTranslator(ctx, msg, in, target)
+		if err == nil {
+			err = w.validateOutput(source, res.Path)
+			w.reportOutcome(res, err)
+		}
+		if err != nil {
+			return res, err
+		}
+		w.remember(ctx, msg, target.Language, res, source)
+		return res, nil
+	}
+
+	merged, pending := applyMemory(source, hits)
+	logger.Infof("🧠 Translation memory: %d/%d cues cached (%s): job_id=%s", len(hits), len(source.Cues), target.Code, msg.JobID)
+
+	outputPath := msg.OutputPath(target.OutputSuffix())
+	if len(pending.Cues) == 0 {
+		res = translator.Result{Path: outputPath, Provider: "memory"}
+	} else {
+		res, err = w.translateRemainder(ctx, msg, pending, target)
+		if err != nil {
+			return res, err
+		}
+		if err := fillPending(merged, pending, res.Path); err != nil {
+			return res, err
+		}
+		res.Path = outputPath
+	}
+
+	if err := subtitle.WriteFile(outputPath, merged); err != nil {
+		return res, fmt.Errorf("write merged output: %w", err)
+	}
+	if err := w.validateOutput(source, outputPath); err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// translateRemainder translates the uncached cues from a staging directory
+// that is kept per job and target, so provider checkpoints survive a retry.
+func (w *Worker) translateRemainder(ctx context.Context, msg types.JobMessage, pending *subtitle.Subtitle, target types.Target) (translator.Result, error) {
+	dir := stageDir(msg.JobID, target.Code)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return translator.Result{}, fmt.Errorf("create staging dir: %w", err)
+	}
+
+	staged := msg
+	staged.SubtitlePath = filepath.Join(dir, filepath.Base(msg.SubtitlePath))
+	if err := subtitle.WriteFile(staged.SubtitlePath, pending); err != nil {
+		return translator.Result{}, fmt.Errorf("write staged subtitle: %w", err)
+	}
+
+	res, err := w.translator.Translate(ctx, staged, target)
+	if err == nil {
+		err = w.validateOutput(pending, res.Path)
+		w.reportOutcome(res, err)
+	}
+	if err != nil {
+		return res, err
+	}
+
+	w.remember(ctx, msg, target.Language, res, pending)
+	return res, nil
+}
+
+// stageDir is where a job's uncached cues are translated into one target.
+func stageDir(jobID, code string) string {
+	return filepath.Join(stagingRoot(), jobID, code)
+}
+
+// fillPending copies the translated remainder into the merged subtitle and
+// removes the staging directory.
+func fillPending(merged, pending *subtitle.Subtitle, translatedPath string) error {
+	out, err := subtitle.ReadFile(translatedPath)
+	if err != nil {
+		return err
+	}
+	for i, c := range pending.Cues {
+		// pending cue indexes point back into merged (see applyMemory)
+		merged.Cues[c.Index].Lines = out.Cues[i].Lines
+	}
+	if err := os.RemoveAll(filepath.Dir(translatedPath)); err != nil {
+		logger.Warnf("Failed to remove staging dir: %v", err)
+	}
+	return nil
+}
+
+// applyMemory returns a copy of source with cached cues filled in, plus the
+// non-empty cues still to translate. Each pending cue's Index is its position
+// in the merged subtitle; WriteSRT renumbers cues, so this is never written.
+func applyMemory(source *subtitle.Subtitle, hits map[int]string) (merged, pending *subtitle.Subtitle) {
+	merged = source.Clone()
+	pending = &subtitle.Subtitle{BOM: source.BOM, CRLF: source.CRLF}
+
+	for i, c := range source.Cues {
+		if text, ok := hits[i]; ok {
+			merged.Cues[i].Lines = strings.Split(text, "\n")
+			continue
+		}
+		if c.IsEmpty() {
+			continue
+		}
+		p := c
+		p.Index = i
+		pending.Cues = append(pending.Cues, p)
+	}
+	return merged, pending
+}
+
+// remember stores freshly translated cues; failures only cost a cache miss.
+func (w *Worker) remember(ctx context.Context, msg types.JobMessage, language string, res translator.Result, src *subtitle.Subtitle) {
+	if res.Model == "" {
+		return
+	}
+	out, err := subtitle.ReadFile(res.Path)
+	if err != nil {
+		logger.Warnf("Translation memory: read output: %v", err)
+		return
+	}
+
+	ref := translator.ModelRef{Provider: res.Provider, Model: res.Model}
+	n, err := w.memory.store(context.WithoutCancel(ctx), language, ref, instructionHash(msg.Instruction), src, out)
+	if err != nil {
+		logger.Warnf("Translation memory store failed: %v", err)
+		return
+	}
+	if n > 0 {
+		logger.Debugf("Translation memory: stored %d lines for %s/%s", n, res.Provider, res.Model)
+	}
+}
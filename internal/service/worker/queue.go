@@ -0,0 +1,272 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/fusionn-subs/internal/types"
+	"github.com/fusionn-subs/pkg/logger"
+)
+
+// Default heartbeat TTL if not configured. A worker whose heartbeat key has
+// expired is considered dead and its processing list is re-queued.
+const defaultHeartbeatTTL = 30 * time.Second
+
+// processingKey is the per-worker list holding jobs that have been taken off
+// the queue but not yet acknowledged.
+func processingKey(queue, workerID string) string {
+	return fmt.Sprintf("%s:processing:%s", queue, workerID)
+}
+
+// heartbeatKey is refreshed periodically while the worker is alive.
+func heartbeatKey(queue, workerID string) string {
+	return fmt.Sprintf("%s:heartbeat:%s", queue, workerID)
+}
+
+// workersKey is the set of worker IDs that may own a processing list.
+func workersKey(queue string) string {
+	return queue + ":workers"
+}
+
+// laneKey is the list holding queued jobs of one priority when priority
+// queues are enabled. Producers may also push to it directly.
+func laneKey(queue, priority string) string {
+	return queue + ":" + priority
+}
+
+// dequeue atomically moves the next job from the queue into this worker's
+// processing list. Producers LPUSH, so the oldest job is on the right.
+//
+// With priority queues enabled, the queue is only an intake: everything on it
+// is first sorted into the priority lanes, and the job is taken from the
+// lanes (see takeFromLanes).
+func (w *Worker) dequeue(ctx context.Context) (string, error) {
+	if !w.cfg.Priority.Enabled {
+		return w.redis.BLMove(ctx, w.cfg.Queue, w.processingKey, "RIGHT", "LEFT", w.cfg.PollTimeout).Result()
+	}
+
+	for {
+		if err := w.sortIntake(ctx); err != nil {
+			return "", err
+		}
+		rawMsg, err := w.takeFromLanes(ctx)
+		if !errors.Is(err, redis.Nil) {
+			return rawMsg, err
+		}
+
+		// Nothing waiting: block on the intake and sort whatever arrives
+		rawMsg, err = w.redis.BLMove(ctx, w.cfg.Queue, w.processingKey, "RIGHT", "LEFT", w.cfg.PollTimeout).Result()
+		if err != nil {
+			return "", err
+		}
+		if err := w.route(ctx, rawMsg); err != nil {
+			return "", err
+		}
+	}
+}
+
+// sortIntake moves every job waiting on the intake queue into its lane. Each
+// job passes through the processing list, so a crash mid-way loses nothing.
+func (w *Worker) sortIntake(ctx context.Context) error {
+	for {
+		rawMsg, err := w.redis.LMove(ctx, w.cfg.Queue, w.processingKey, "RIGHT", "LEFT").Result()
+		if errors.Is(err, redis.Nil) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := w.route(ctx, rawMsg); err != nil {
+			return err
+		}
+	}
+}
+
+// route moves a job from the processing list to the lane of its priority.
+// Unparseable jobs go to the normal lane and are dead-lettered when taken.
+func (w *Worker) route(ctx context.Context, rawMsg string) error {
+	priority := types.PriorityNormal
+	var msg types.JobMessage
+	if err := json.Unmarshal([]byte(rawMsg), &msg); err == nil {
+		priority = w.priorityOf(msg)
+	}
+
+	_, err := w.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.LPush(ctx, laneKey(w.cfg.Queue, priority), rawMsg)
+		pipe.LRem(ctx, w.processingKey, 1, rawMsg)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("route job to %s lane: %w", priority, err)
+	}
+	logger.Debugf("Queued %s job %s in %s lane", msg.MediaType, msg.JobID, priority)
+	return nil
+}
+
+// takeFromLanes moves the oldest job of the highest-priority lane that has
+// one into the processing list. Every StarvationLimit-th job is taken from
+// the lowest lane with work instead, so a steady stream of movies cannot hold
+// episodes back indefinitely. Returns redis.Nil if all lanes are empty.
+func (w *Worker) takeFromLanes(ctx context.Context) (string, error) {
+	order := types.Priorities
+	if (w.laneTurns.Load()+1)%uint64(w.cfg.Priority.StarvationLimit) == 0 {
+		order = slices.Clone(order)
+		slices.Reverse(order)
+	}
+
+	for _, p := range order {
+		rawMsg, err := w.redis.LMove(ctx, laneKey(w.cfg.Queue, p), w.processingKey, "RIGHT", "LEFT").Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		w.laneTurns.Add(1)
+		return rawMsg, nil
+	}
+	return "", redis.Nil
+}
+
+// priorityOf returns the job's own priority, or the default for its media type.
+func (w *Worker) priorityOf(msg types.JobMessage) string {
+	if types.IsPriority(msg.Priority) {
+		return msg.Priority
+	}
+	if p, ok := w.cfg.Priority.MediaTypes[strings.ToLower(msg.MediaType)]; ok {
+		return p
+	}
+	return types.PriorityNormal
+}
+
+// ack removes a job from the processing list once it has been fully handled.
+// Uses a non-cancelable context so a shutdown racing a finished job does not
+// leave it behind to be replayed.
+func (w *Worker) ack(ctx context.Context, rawMsg string) {
+	if err := w.redis.LRem(context.WithoutCancel(ctx), w.processingKey, 1, rawMsg).Err(); err != nil {
+		logger.Errorf("Failed to acknowledge job: %v", err)
+	}
+}
+
+// recoverStale re-queues jobs left in the processing lists of dead workers.
+// At startup (self set) this worker's own list is treated as stale too: a
+// previous incarnation with the same ID was killed before it could acknowledge
+// its jobs. Afterwards it holds the jobs in flight and is left alone.
+func (w *Worker) recoverStale(ctx context.Context, self bool) error {
+	ids, err := w.redis.SMembers(ctx, workersKey(w.cfg.Queue)).Result()
+	if err != nil {
+		return fmt.Errorf("list workers: %w", err)
+	}
+
+	for _, id := range ids {
+		if id == w.cfg.WorkerID && !self {
+			continue
+		}
+		if id != w.cfg.WorkerID {
+			alive, err := w.redis.Exists(ctx, heartbeatKey(w.cfg.Queue, id)).Result()
+			if err != nil {
+				return fmt.Errorf("check heartbeat for %s: %w", id, err)
+			}
+			if alive > 0 {
+				continue
+			}
+		}
+
+		requeued, err := w.requeueAll(ctx, processingKey(w.cfg.Queue, id))
+		if err != nil {
+			return fmt.Errorf("requeue jobs from %s: %w", id, err)
+		}
+		if requeued > 0 {
+			logger.Warnf("♻️  Recovered %d unacknowledged job(s) from worker %s", requeued, id)
+		}
+
+		if id != w.cfg.WorkerID {
+			if err := w.redis.SRem(ctx, workersKey(w.cfg.Queue), id).Err(); err != nil {
+				return fmt.Errorf("unregister worker %s: %w", id, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// requeueAll moves every entry of a processing list back onto the consuming
+// end of the queue, preserving the original processing order.
+func (w *Worker) requeueAll(ctx context.Context, key string) (int, error) {
+	count := 0
+	for {
+		err := w.redis.LMove(ctx, key, w.cfg.Queue, "LEFT", "RIGHT").Err()
+		if errors.Is(err, redis.Nil) {
+			return count, nil
+		}
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+}
+
+// register writes this worker's first heartbeat and announces it, in one
+// transaction with the heartbeat first, so no other worker's recovery sees it
+// registered without a heartbeat.
+func (w *Worker) register(ctx context.Context) error {
+	_, err := w.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, heartbeatKey(w.cfg.Queue, w.cfg.WorkerID), time.Now().Unix(), w.cfg.HeartbeatTTL)
+		pipe.SAdd(ctx, workersKey(w.cfg.Queue), w.cfg.WorkerID)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("register worker: %w", err)
+	}
+	return nil
+}
+
+func (w *Worker) beat(ctx context.Context) error {
+	return w.redis.Set(ctx, heartbeatKey(w.cfg.Queue, w.cfg.WorkerID), time.Now().Unix(), w.cfg.HeartbeatTTL).Err()
+}
+
+// runHeartbeat refreshes the heartbeat key until ctx is canceled, then removes
+// it so other workers can recover anything left unacknowledged.
+func (w *Worker) runHeartbeat(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.HeartbeatTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := w.redis.Del(context.WithoutCancel(ctx), heartbeatKey(w.cfg.Queue, w.cfg.WorkerID)).Err(); err != nil {
+				logger.Warnf("Failed to clear heartbeat: %v", err)
+			}
+			return
+		case <-ticker.C:
+			if err := w.beat(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				logger.Warnf("Heartbeat failed: %v", err)
+			}
+		}
+	}
+}
+
+// runRecovery re-queues the jobs of workers that died while this one runs,
+// checking once per heartbeat TTL until ctx is canceled.
+func (w *Worker) runRecovery(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.HeartbeatTTL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.recoverStale(ctx, false); err != nil && !errors.Is(err, context.Canceled) {
+				logger.Warnf("Stale job recovery failed: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/fusionn-subs/internal/config"
+	"github.com/fusionn-subs/internal/types"
+)
+
+func newTestWorker(t *testing.T, cfg Config) *Worker {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	if cfg.Queue == "" {
+		cfg.Queue = "jobs"
+	}
+	if cfg.WorkerID == "" {
+		cfg.WorkerID = "test"
+	}
+	return New(client, cfg, nil, nil)
+}
+
+func TestTakeFromLanesStarvation(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit int
+		lanes map[string]int // Jobs queued per lane
+		want  string         // Lanes the jobs are taken from, in order
+	}{
+		{
+			name:  "every third job from the lowest lane",
+			limit: 3,
+			lanes: map[string]int{types.PriorityHigh: 6, types.PriorityLow: 3},
+			want:  "high high low high high low high high low",
+		},
+		{
+			name:  "normal lane when the low lane is empty",
+			limit: 2,
+			lanes: map[string]int{types.PriorityHigh: 3, types.PriorityNormal: 2},
+			want:  "high normal high normal high",
+		},
+		{
+			name:  "highest lane when the others are empty",
+			limit: 2,
+			lanes: map[string]int{types.PriorityHigh: 4},
+			want:  "high high high high",
+		},
+		{
+			name:  "limit of one favours the lowest lane",
+			limit: 1,
+			lanes: map[string]int{types.PriorityHigh: 2, types.PriorityLow: 2},
+			want:  "low low high high",
+		},
+		{
+			name:  "default limit",
+			lanes: map[string]int{types.PriorityHigh: 5, types.PriorityNormal: 2},
+			want:  "high high high high normal high normal",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorker(t, Config{Priority: config.PriorityConfig{Enabled: true, StarvationLimit: tt.limit}})
+			ctx := context.Background()
+
+			total := 0
+			for lane, n := range tt.lanes {
+				for i := range n {
+					if err := w.redis.LPush(ctx, laneKey(w.cfg.Queue, lane), fmt.Sprintf("%s/%d", lane, i)).Err(); err != nil {
+						t.Fatal(err)
+					}
+				}
+				total += n
+			}
+
+			var got []string
+			for i := range total {
+				rawMsg, err := w.takeFromLanes(ctx)
+				if err != nil {
+					t.Fatalf("take %d: %v", i+1, err)
+				}
+				lane, _, _ := strings.Cut(rawMsg, "/")
+				got = append(got, lane)
+			}
+			if strings.Join(got, " ") != tt.want {
+				t.Errorf("taken from %q, want %q", got, tt.want)
+			}
+
+			if _, err := w.takeFromLanes(ctx); !errors.Is(err, redis.Nil) {
+				t.Errorf("empty lanes: err = %v, want redis.Nil", err)
+			}
+			if n, _ := w.redis.LLen(ctx, w.processingKey).Result(); n != int64(total) {
+				t.Errorf("processing list has %d jobs, want %d", n, total)
+			}
+		})
+	}
+}
+
+func TestTakeFromLanesOldestFirst(t *testing.T) {
+	w := newTestWorker(t, Config{Priority: config.PriorityConfig{Enabled: true}})
+	ctx := context.Background()
+	for _, job := range []string{"first", "second", "third"} {
+		if err := w.redis.LPush(ctx, laneKey(w.cfg.Queue, types.PriorityNormal), job).Err(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, want := range []string{"first", "second", "third"} {
+		got, err := w.takeFromLanes(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("took %q, want %q", got, want)
+		}
+	}
+}
+
+func TestPriorityOf(t *testing.T) {
+	w := newTestWorker(t, Config{Priority: config.PriorityConfig{Enabled: true}})
+	tests := []struct {
+		msg  types.JobMessage
+		want string
+	}{
+		{types.JobMessage{MediaType: "movie"}, types.PriorityHigh},
+		{types.JobMessage{MediaType: "Episode"}, types.PriorityNormal},
+		{types.JobMessage{MediaType: "clip"}, types.PriorityNormal},
+		{types.JobMessage{MediaType: "movie", Priority: types.PriorityLow}, types.PriorityLow},
+		{types.JobMessage{MediaType: "movie", Priority: "urgent"}, types.PriorityHigh},
+	}
+	for _, tt := range tests {
+		if got := w.priorityOf(tt.msg); got != tt.want {
+			t.Errorf("priorityOf(%+v) = %q, want %q", tt.msg, got, tt.want)
+		}
+	}
+}
+
+func TestRecoverStale(t *testing.T) {
+	w := newTestWorker(t, Config{HeartbeatTTL: time.Minute})
+	ctx := context.Background()
+	for id, jobs := range map[string][]string{"test": {"own"}, "alive": {"running"}, "dead": {"lost-1", "lost-2"}} {
+		w.redis.SAdd(ctx, workersKey(w.cfg.Queue), id)
+		for _, job := range jobs {
+			w.redis.LPush(ctx, processingKey(w.cfg.Queue, id), job)
+		}
+	}
+	w.redis.Set(ctx, heartbeatKey(w.cfg.Queue, "alive"), 1, time.Minute)
+
+	// While running, only dead workers are recovered
+	if err := w.recoverStale(ctx, false); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := w.redis.LRange(ctx, w.cfg.Queue, 0, -1).Result(); strings.Join(got, " ") != "lost-2 lost-1" {
+		t.Errorf("queue = %q, want the dead worker's jobs", got)
+	}
+	if dead, _ := w.redis.SIsMember(ctx, workersKey(w.cfg.Queue), "dead").Result(); dead {
+		t.Error("dead worker still registered")
+	}
+	for id, want := range map[string]int64{"test": 1, "alive": 1} {
+		if n, _ := w.redis.LLen(ctx, processingKey(w.cfg.Queue, id)).Result(); n != want {
+			t.Errorf("%s processing list has %d jobs, want %d", id, n, want)
+		}
+	}
+
+	// At startup this worker's own list is recovered as well
+	if err := w.recoverStale(ctx, true); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := w.redis.LLen(ctx, w.processingKey).Result(); n != 0 {
+		t.Errorf("own processing list has %d jobs after startup recovery", n)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	w := newTestWorker(t, Config{HeartbeatTTL: time.Minute})
+	ctx := context.Background()
+	if err := w.register(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if ttl, _ := w.redis.TTL(ctx, heartbeatKey(w.cfg.Queue, w.cfg.WorkerID)).Result(); ttl <= 0 {
+		t.Errorf("heartbeat TTL = %v, want it set", ttl)
+	}
+	if ok, _ := w.redis.SIsMember(ctx, workersKey(w.cfg.Queue), w.cfg.WorkerID).Result(); !ok {
+		t.Error("worker not registered")
+	}
+}
@@ -0,0 +1,87 @@
+package worker
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/fusionn-subs/internal/service/translator"
+	"github.com/fusionn-subs/internal/subtitle"
+	"github.com/fusionn-subs/internal/types"
+	"github.com/fusionn-subs/pkg/logger"
+)
+
+// errInvalidOutput marks a translation whose output failed validation. It is
+// not a translator sentinel, so the retry loop treats it as retryable.
+var errInvalidOutput = errors.New("translated output failed validation")
+
+// validateSource checks the job message and, for SRT, ASS and WebVTT input,
+// parses the subtitle to make sure it has usable cues. The parsed subtitle is returned
+// for output validation; it is nil for formats that are not parsed natively.
+func validateSource(msg types.JobMessage) (*subtitle.Subtitle, error) {
+	if err := msg.Validate(); err != nil {
+		return nil, err
+	}
+
+	if !subtitle.IsSupported(msg.SubtitlePath) {
+		return nil, nil
+	}
+
+	sub, err := subtitle.ReadFile(msg.SubtitlePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := sub.Validate(); err != nil {
+		return nil, err
+	}
+
+	logger.Debugf("Source subtitle OK: %d cues", len(sub.Cues))
+	return sub, nil
+}
+
+// validateOutput compares a translated file against its source: cue count,
+// timing alignment and the share of cues that were left untranslated. A
+// rejected output is deleted so it cannot be delivered by a later attempt.
+func (w *Worker) validateOutput(source *subtitle.Subtitle, outputPath string) error {
+	if source == nil || !subtitle.IsSupported(outputPath) {
+		return nil
+	}
+
+	err := w.checkOutput(source, outputPath)
+	if err == nil {
+		return nil
+	}
+
+	if rmErr := os.Remove(outputPath); rmErr != nil && !errors.Is(rmErr, os.ErrNotExist) {
+		logger.Warnf("Failed to remove rejected output %s: %v", outputPath, rmErr)
+	}
+	return fmt.Errorf("%w: %w", errInvalidOutput, err)
+}
+
+// reportOutcome passes the validation result of a translator's output to
+// cfg.OnOutcome. Translator errors are reported by the providers themselves.
+func (w *Worker) reportOutcome(res translator.Result, err error) {
+	if w.cfg.OnOutcome == nil || res.Model == "" {
+		return
+	}
+	w.cfg.OnOutcome(res.Provider, res.Model, err)
+}
+
+func (w *Worker) checkOutput(source *subtitle.Subtitle, outputPath string) error {
+	out, err := subtitle.ReadFile(outputPath)
+	if err != nil {
+		return err
+	}
+
+	if err := subtitle.CheckAligned(source, out); err != nil {
+		return err
+	}
+
+	ratio := subtitle.UntranslatedRatio(source, out)
+	if ratio > w.cfg.MaxUntranslatedRatio {
+		return fmt.Errorf("%.0f%% of cues untranslated (max %.0f%%)", ratio*100, w.cfg.MaxUntranslatedRatio*100)
+	}
+
+	logger.Debugf("Output subtitle OK: %d cues, %.0f%% untranslated", len(out.Cues), ratio*100)
+	return nil
+}
@@ -5,11 +5,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 
 	"github.com/fusionn-subs/internal/client/callback"
+	"github.com/fusionn-subs/internal/config"
+	"github.com/fusionn-subs/internal/service/extract"
 	"github.com/fusionn-subs/internal/service/translator"
 	"github.com/fusionn-subs/internal/types"
 	"github.com/fusionn-subs/pkg/logger"
@@ -20,49 +25,176 @@ const (
 	initialBackoff = time.Second
 	maxBackoff     = 30 * time.Second
 	backoffFactor  = 2
+
+	defaultDrainTimeout         = 2 * time.Minute
+	defaultMaxUntranslatedRatio = 0.2
+	defaultStarvationLimit      = 5
 )
 
 type Config struct {
 	Queue                 string
 	PollTimeout           time.Duration
 	MaxTranslationRetries int
+	WorkerID              string         // Unique per running instance; owns a processing list
+	HeartbeatTTL          time.Duration  // Liveness window used by crash recovery
+	DeadLetterQueue       string         // List receiving jobs that could not be completed
+	Concurrency           int            // Number of consumer goroutines sharing the queue
+	DrainTimeout          time.Duration  // How long in-flight jobs may run after shutdown is requested
+	MaxUntranslatedRatio  float64        // Share of cues left identical to the source before output is rejected
+	Targets               []types.Target // Languages produced for jobs that do not list their own
+	Memory                config.MemoryConfig
+	IdempotencyTTL        time.Duration // How long finished jobs are remembered for deduplication
+	Priority              config.PriorityConfig
+	Bilingual             config.BilingualConfig
+	ASSFont               string // Font of translated ASS dialogue for targets without their own
+	OutputFormat          string // "srt", "vtt" or "ass"; empty keeps the source format
+	Cleanup               config.CleanupConfig
+	Providers             []string // Provider chain in fallback order, for picking cleanup rules
+	Encoding              config.EncodingConfig
+	OnOutcome             func(provider, model string, err error) // Receives each translation's output validation result, e.g. for model demotion
 }
 
 type Worker struct {
-	redis      *redis.Client
-	cfg        Config
-	translator translator.Translator
-	callback   *callback.Client
+	redis         *redis.Client
+	cfg           Config
+	translator    translator.Translator
+	callback      *callback.Client
+	processingKey string
+	memory        *memory // nil when the translation memory is disabled
+	extractor     *extract.Extractor
+	inflight      sync.Map      // Job IDs claimed by this process
+	laneTurns     atomic.Uint64 // Jobs taken from priority lanes, for starvation protection
 }
 
 func New(redisClient *redis.Client, cfg Config, trans translator.Translator, callbackClient *callback.Client) *Worker {
-	return &Worker{
-		redis:      redisClient,
-		cfg:        cfg,
-		translator: trans,
-		callback:   callbackClient,
+	if cfg.HeartbeatTTL <= 0 {
+		cfg.HeartbeatTTL = defaultHeartbeatTTL
+	}
+	if cfg.DeadLetterQueue == "" {
+		cfg.DeadLetterQueue = cfg.Queue + ":dead"
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+	if cfg.DrainTimeout <= 0 {
+		cfg.DrainTimeout = defaultDrainTimeout
+	}
+	if cfg.IdempotencyTTL <= 0 {
+		cfg.IdempotencyTTL = defaultIdempotencyTTL
+	}
+	if cfg.MaxUntranslatedRatio <= 0 {
+		cfg.MaxUntranslatedRatio = defaultMaxUntranslatedRatio
 	}
+	if cfg.Priority.StarvationLimit <= 0 {
+		cfg.Priority.StarvationLimit = defaultStarvationLimit
+	}
+	if len(cfg.Priority.MediaTypes) == 0 {
+		cfg.Priority.MediaTypes = map[string]string{"movie": types.PriorityHigh, "episode": types.PriorityNormal}
+	}
+	w := &Worker{
+		redis:         redisClient,
+		cfg:           cfg,
+		translator:    trans,
+		callback:      callbackClient,
+		processingKey: processingKey(cfg.Queue, cfg.WorkerID),
+		extractor:     extract.New(),
+	}
+	if cfg.Memory.Enabled {
+		prefix := cfg.Memory.KeyPrefix
+		if prefix == "" {
+			prefix = config.DefaultMemoryKeyPrefix
+		}
+		w.memory = &memory{redis: redisClient, prefix: prefix, ttl: cfg.Memory.TTL}
+	}
+	return w
 }
 
+// Run starts the consumers and blocks until ctx is canceled. On cancellation
+// no new jobs are taken; in-flight jobs get up to DrainTimeout to finish before
+// they are interrupted and left for crash recovery.
 func (w *Worker) Run(ctx context.Context) error {
+	if err := w.recoverStale(ctx, true); err != nil {
+		return fmt.Errorf("recover stale jobs: %w", err)
+	}
+	if err := w.register(ctx); err != nil {
+		return err
+	}
+	sweepStaging(w.cfg.IdempotencyTTL)
+
+	heartbeatCtx, stopHeartbeat := context.WithCancel(context.WithoutCancel(ctx))
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		w.runHeartbeat(heartbeatCtx)
+	}()
+
+	recoveryDone := make(chan struct{})
+	go func() {
+		defer close(recoveryDone)
+		w.runRecovery(ctx)
+	}()
+
+	// Jobs run on a context detached from ctx so a shutdown signal does not
+	// abort them immediately.
+	jobCtx, cancelJobs := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelJobs()
+
+	var wg sync.WaitGroup
+	for i := range w.cfg.Concurrency {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			w.consume(ctx, jobCtx, id)
+		}(i + 1)
+	}
+
+	consumersDone := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(consumersDone)
+	}()
+
+	select {
+	case <-consumersDone:
+	case <-ctx.Done():
+		logger.Infof("🛑 Shutdown requested, draining in-flight jobs (up to %v)...", w.cfg.DrainTimeout)
+		select {
+		case <-consumersDone:
+			logger.Info("✅ All in-flight jobs drained")
+		case <-time.After(w.cfg.DrainTimeout):
+			logger.Warnf("⏱️  Drain timeout reached, interrupting remaining jobs")
+			cancelJobs()
+			<-consumersDone
+		}
+	}
+
+	<-recoveryDone
+	stopHeartbeat()
+	<-heartbeatDone
+
+	return ctx.Err()
+}
+
+// consume takes jobs off the queue until ctx is canceled.
+func (w *Worker) consume(ctx, jobCtx context.Context, id int) {
 	backoff := initialBackoff
 
 	for {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return
 		default:
-			err := w.processNext(ctx)
+			err := w.processNext(ctx, jobCtx)
 			if err != nil {
 				if errors.Is(err, context.Canceled) {
-					return err
+					return
 				}
 
 				// Exponential backoff for connection errors
-				logger.Errorf("Worker error: %v (retry in %v)", err, backoff)
+				logger.Errorf("Worker %d error: %v (retry in %v)", id, err, backoff)
 				select {
 				case <-ctx.Done():
-					return ctx.Err()
+					return
 				case <-time.After(backoff):
 				}
 
@@ -75,8 +207,9 @@ func (w *Worker) Run(ctx context.Context) error {
 	}
 }
 
-func (w *Worker) processNext(ctx context.Context) error {
-	result, err := w.redis.BRPop(ctx, w.cfg.PollTimeout, w.cfg.Queue).Result()
+// processNext waits for a job using ctx and processes it using jobCtx.
+func (w *Worker) processNext(ctx, jobCtx context.Context) error {
+	rawMsg, err := w.dequeue(ctx)
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
 			return nil // Timeout, no message - this is normal
@@ -84,36 +217,159 @@ func (w *Worker) processNext(ctx context.Context) error {
 		return err // Connection error - will trigger backoff
 	}
 
-	if len(result) < 2 {
-		logger.Warn("Redis returned unexpected payload format")
-		return nil
-	}
+	receivedAt := time.Now()
 
-	rawMsg := result[1]
 	var msg types.JobMessage
 	if err := json.Unmarshal([]byte(rawMsg), &msg); err != nil {
-		logger.Errorf("Failed to parse message (dropping): %v", err)
+		logger.Errorf("Failed to parse message (dead-lettering): %v", err)
+		w.deadLetter(ctx, rawMsg, msg, &jobFailure{err: fmt.Errorf("parse message: %w", err), startedAt: receivedAt})
 		return nil // Bad message, don't retry
 	}
 
 	logger.Infof("📥 Message received: %s (%s) [job: %s]", msg.MediaTitle, msg.MediaType, msg.JobID)
+	if msg.Provider != "" || msg.Instruction != "" || msg.Priority != "" {
+		logger.Infof("🎛️  Job overrides: provider=%q model=%q priority=%q custom instruction=%v: job_id=%s",
+			msg.Provider, msg.Model, msg.Priority, msg.Instruction != "", msg.JobID)
+	}
+
+	// The lane's priority also orders the job for provider slots
+	if w.cfg.Priority.Enabled && msg.Priority == "" {
+		msg.Priority = w.priorityOf(msg)
+	}
+
+	if msg.JobID != "" {
+		claimed, err := w.claim(ctx, msg.JobID)
+		if err != nil {
+			return err // Redis error - leave the job for recovery and back off
+		}
+		if !claimed {
+			logger.Infof("⏭️  Duplicate skipped, job already in progress: job_id=%s", msg.JobID)
+			w.ack(ctx, rawMsg)
+			return nil
+		}
+		defer w.release(ctx, msg.JobID)
+	}
 
 	// Process the job
-	if err := w.processJob(ctx, msg); err != nil {
+	if err := w.processJob(jobCtx, msg); err != nil {
+		if jobCtx.Err() != nil {
+			// Shutting down mid-job: leave it in the processing list so it is
+			// re-queued by crash recovery on the next start.
+			logger.Warnf("⏸️  Job interrupted, will be recovered on restart: job_id=%s", msg.JobID)
+			return jobCtx.Err()
+		}
 		logger.Errorf("❌ Job failed for %s: %v", msg.SubtitlePath, err)
-		// Note: Message is already consumed. Consider implementing:
-		// - Dead letter queue for failed jobs
-		// - Retry with LPUSH back to queue
+
+		var failure *jobFailure
+		if !errors.As(err, &failure) {
+			failure = &jobFailure{err: err}
+		}
+		failure.startedAt = receivedAt
+		w.deadLetter(ctx, rawMsg, msg, failure)
 		return nil
 	}
 
+	w.ack(ctx, rawMsg)
 	return nil
 }
 
 func (w *Worker) processJob(ctx context.Context, msg types.JobMessage) error {
+	// Jobs without a sidecar are translated from a track embedded in the video
+	if msg.SubtitlePath == "" {
+		if err := msg.Validate(); err != nil {
+			logger.Errorf("❌ Invalid job: job_id=%s: %v", msg.JobID, err)
+			return &jobFailure{err: fmt.Errorf("invalid job: %w", err)}
+		}
+		path, err := w.extractSubtitle(ctx, msg)
+		if err != nil && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			logger.Errorf("❌ Subtitle extraction failed: job_id=%s: %v", msg.JobID, err)
+			return &jobFailure{err: fmt.Errorf("extract subtitle: %w", err)}
+		}
+		msg.SubtitlePath = path
+	}
+
+	// Reject broken input before spending any translation quota
+	source, err := validateSource(msg)
+	if err != nil {
+		logger.Errorf("❌ Invalid source subtitle: job_id=%s: %v", msg.JobID, err)
+		return &jobFailure{err: fmt.Errorf("invalid source: %w", err)}
+	}
+
+	// Translators only read UTF-8
+	in, removeDecoded, err := w.decodeSource(msg, jobInput{source: source, path: msg.SubtitlePath})
+	if err != nil {
+		logger.Errorf("❌ Unreadable source subtitle: job_id=%s: %v", msg.JobID, err)
+		return &jobFailure{err: fmt.Errorf("invalid source: %w", err)}
+	}
+
+	in.hash, err = fileHash(msg.SubtitlePath)
+	if err != nil {
+		logger.Warnf("Failed to hash source, skipping content dedupe: %v", err)
+	}
+
+	in, removeCleaned, err := w.cleanSource(msg, in)
+	if err != nil {
+		logger.Errorf("❌ Subtitle cleanup failed: job_id=%s: %v", msg.JobID, err)
+		return &jobFailure{err: fmt.Errorf("cleanup: %w", err)}
+	}
+
+	targets := msg.JobTargets(w.cfg.Targets)
+
+	// Each target is translated and recorded on its own, so a job replayed
+	// after a partial failure only translates the targets still missing.
+	outputs := make(map[string]targetFiles, len(targets))
+	attempts := 0
+	for _, target := range targets {
+		files, n, err := w.processTarget(ctx, msg, in, target)
+		attempts = max(attempts, n)
+		if err != nil {
+			return err
+		}
+		outputs[target.Code] = files
+	}
+
+	if err := w.sendCallback(ctx, msg, targets, outputs, attempts); err != nil {
+		return err
+	}
+
+	// Kept until now so a failed job resumes from its checkpoints
+	removeCleaned()
+	removeDecoded()
+	return nil
+}
+
+// processTarget produces the job's output for one target language, reusing an
+// earlier translation when possible. It returns the delivered files and the
+// number of translation attempts made.
+func (w *Worker) processTarget(ctx context.Context, msg types.JobMessage, in jobInput, target types.Target) (targetFiles, int, error) {
+	files := w.expectedFiles(msg, in.source, target)
+	layout := w.bilingualLayout(in.source)
+
+	// Short-circuit targets that were already translated
+	rec, reason, err := w.findCompletion(ctx, msg, in, target, layout, files)
+	if err != nil {
+		logger.Warnf("Idempotency lookup failed, translating: %v", err)
+	}
+	if rec != nil {
+		err := w.reuseOutput(files, rec)
+		if err == nil {
+			logger.Infof("🔁 Duplicate by %s (first done by job %s at %s), reusing %s output: job_id=%s",
+				reason, rec.JobID, rec.CompletedAt.Format(time.RFC3339), target.Code, msg.JobID)
+			w.recordCompletion(ctx, msg, in, target, layout, files)
+			return files, 0, nil
+		}
+		logger.Warnf("Cannot reuse earlier output, translating: %v", err)
+	}
+
+	logger.Infof("🌐 Translating into %s (%s): job_id=%s", target.Language, target.Code, msg.JobID)
+
 	// Translate with retry logic
-	var chsPath string
+	var result translator.Result
 	var lastErr error
+	attempts := 0
 
 	maxRetries := w.cfg.MaxTranslationRetries
 	if maxRetries <= 0 {
@@ -125,9 +381,11 @@ func (w *Worker) processJob(ctx context.Context, msg types.JobMessage) error {
 			logger.Infof("⏳ Translation retry %d/%d: job_id=%s", attempt-1, maxRetries-1, msg.JobID)
 		}
 
+		attempts = attempt
 		var err error
-		chsPath, err = w.translator.Translate(ctx, msg)
+		result, err = w.translate(ctx, msg, in, target)
 		if err == nil {
+			lastErr = nil
 			if attempt > 1 {
 				logger.Infof("✅ Translation succeeded on attempt %d", attempt)
 			}
@@ -145,31 +403,77 @@ func (w *Worker) processJob(ctx context.Context, msg types.JobMessage) error {
 			select {
 			case <-time.After(2 * time.Second):
 			case <-ctx.Done():
-				return ctx.Err()
+				return targetFiles{}, attempts, ctx.Err()
 			}
 		}
 	}
 
 	if lastErr != nil {
+		if w.memory != nil {
+			os.RemoveAll(stageDir(msg.JobID, target.Code))
+		}
 		if errors.Is(lastErr, translator.ErrAllModelsExhausted) {
 			logger.Errorf("❌ All models exhausted: job_id=%s", msg.JobID)
-			return fmt.Errorf("all models exhausted: %w", lastErr)
+			return targetFiles{}, attempts, &jobFailure{attempts: attempts, err: fmt.Errorf("all models exhausted: %w", lastErr)}
 		}
-		logger.Errorf("❌ Translation failed after %d attempts: job_id=%s", maxRetries, msg.JobID)
-		return fmt.Errorf("translation failed after %d attempts: %w", maxRetries, lastErr)
+		logger.Errorf("❌ Translation failed after %d attempts: job_id=%s", attempts, msg.JobID)
+		return targetFiles{}, attempts, &jobFailure{attempts: attempts, err: fmt.Errorf("translation into %s failed after %d attempts: %w", target.Code, attempts, lastErr)}
 	}
 
+	translation, err := w.convertOutput(result.Path)
+	if err == nil {
+		err = w.applyFont(translation, target)
+	}
+	if err != nil {
+		logger.Errorf("❌ Writing the output failed: job_id=%s: %v", msg.JobID, err)
+		return targetFiles{}, attempts, &jobFailure{attempts: attempts, err: fmt.Errorf("output for %s: %w", target.Code, err)}
+	}
+
+	if files.Bilingual != "" {
+		if err := w.writeBilingual(in.source, translation, files, target); err != nil {
+			logger.Errorf("❌ Bilingual output failed: job_id=%s: %v", msg.JobID, err)
+			return targetFiles{}, attempts, &jobFailure{attempts: attempts, err: fmt.Errorf("bilingual output for %s: %w", target.Code, err)}
+		}
+	} else {
+		files.Path = translation
+	}
+
+	if err := w.encodeOutput(files, in.encoding); err != nil {
+		logger.Errorf("❌ Encoding the output failed: job_id=%s: %v", msg.JobID, err)
+		return targetFiles{}, attempts, &jobFailure{attempts: attempts, err: fmt.Errorf("output encoding for %s: %w", target.Code, err)}
+	}
+
+	// Recorded before the callback so a failed callback replayed from the
+	// dead-letter queue does not translate again.
+	w.recordCompletion(ctx, msg, in, target, layout, files)
+
+	return files, attempts, nil
+}
+
+func (w *Worker) sendCallback(ctx context.Context, msg types.JobMessage, targets []types.Target, outputs map[string]targetFiles, attempts int) error {
 	payload := callback.Payload{
 		JobID:           msg.JobID,
 		VideoPath:       msg.VideoPath,
 		EngSubtitlePath: msg.SubtitlePath,
-		ChsSubtitlePath: chsPath,
+		ChsSubtitlePath: outputs[targets[0].Code].Path,
+		Subtitles:       make(map[string]string, len(outputs)),
+	}
+	for code, files := range outputs {
+		payload.Subtitles[code] = files.Path
+		if files.Bilingual != "" && files.Bilingual != files.Path {
+			if payload.Bilingual == nil {
+				payload.Bilingual = make(map[string]string, len(outputs))
+			}
+			payload.Bilingual[code] = files.Bilingual
+		}
 	}
 
 	if err := w.callback.Send(ctx, payload); err != nil {
-		return err
+		return &jobFailure{attempts: attempts, err: fmt.Errorf("callback: %w", err)}
 	}
 
-	logger.Infof("✅ Completed: %s", chsPath)
+	for _, t := range targets {
+		logger.Infof("✅ Completed: %s", outputs[t.Code].Path)
+	}
 	return nil
 }
@@ -0,0 +1,387 @@
+package subtitle
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotASS marks input that has no [Events] section with dialogue format.
+var ErrNotASS = errors.New("not an ASS/SSA script")
+
+const defaultASSFont = "Arial"
+
+// assHeader is the script header written for subtitles converted to ASS. The
+// placeholder is the font of the default style.
+const assHeader = `[Script Info]
+; Written by fusionn-subs
+ScriptType: v4.00+
+PlayResX: 384
+PlayResY: 288
+WrapStyle: 0
+ScaledBorderAndShadow: yes
+
+[V4+ Styles]
+Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding
+Style: Default,%s,16,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,1,0,2,10,10,10,1
+
+[Events]
+Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text
+`
+
+// Field layouts used when a section has no Format line.
+var (
+	defaultStyleFormat = []string{"name", "fontname"}
+	defaultEventFormat = []string{"layer", "start", "end", "style", "name", "marginl", "marginr", "marginv", "effect", "text"}
+)
+
+// assScript is everything of a parsed ASS/SSA file except the translatable
+// dialogue text: script info, styles, comments, fonts and the events that are
+// not translated. Writing a Subtitle that carries it reproduces the file with
+// only the dialogue text replaced.
+type assScript struct {
+	lines   []string   // Original lines without line endings
+	events  []assEvent // Translated events, one per cue
+	styles  []assStyle
+	dropped map[int]bool // Lines of events removed by a cleanup
+}
+
+// assEvent is the untranslated part of a Dialogue line.
+type assEvent struct {
+	line   int    // Index into lines
+	head   string // Everything before the Text field, verbatim
+	prefix string // Leading override blocks, e.g. {\an8\fad(200,200)}
+	style  string
+}
+
+type assStyle struct {
+	line   int
+	key    string // "Style"
+	name   string
+	fields []string // Values in Format order
+	font   int      // Index of the Fontname field
+}
+
+var (
+	assBlockPrefix  = regexp.MustCompile(`^(\{[^}]*\})+`)
+	assBlockPattern = regexp.MustCompile(`\{[^}]*\}`)
+	assToggleBlock  = regexp.MustCompile(`\{\\([bius])([01]?)\}`)
+	assKaraokeTag   = regexp.MustCompile(`\\(k|K|kf|ko)\d`)
+	assDrawingTag   = regexp.MustCompile(`\\p[1-9]`)
+	assSignTag      = regexp.MustCompile(`\\(pos|move)\(`)
+	assSignStyle    = regexp.MustCompile(`(?i)(^|[^a-z])(signs?|titles?)([^a-z]|$)`)
+	assTimestampPat = regexp.MustCompile(`^\s*(\d+):(\d{1,2}):(\d{1,2})[.:](\d{1,3})\s*$`)
+)
+
+// IsASS reports whether path is an ASS or SSA script by its extension.
+func IsASS(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ass", ".ssa":
+		return true
+	}
+	return false
+}
+
+// ParseASS parses an ASS or SSA script. Dialogue events become cues whose
+// lines are the event text with \N breaks split into lines and bold, italic,
+// underline and strikeout toggles written as SRT tags; leading override
+// blocks are kept aside and restored on write. Karaoke and drawing events and
+// typesetting (events positioned with \pos or \move, or using a style named
+// Sign or Title) are not translated and are kept unchanged, as are comments
+// and all other sections.
+func ParseASS(data []byte) (*Subtitle, error) {
+	sub := &Subtitle{}
+
+	if bytes.HasPrefix(data, utf8BOM) {
+		sub.BOM = true
+		data = data[len(utf8BOM):]
+	}
+
+	text := string(data)
+	if strings.Contains(text, "\r\n") {
+		sub.CRLF = true
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+	}
+	text = strings.TrimSuffix(text, "\n")
+
+	script := &assScript{lines: strings.Split(text, "\n")}
+	var section string
+	styleFormat, eventFormat := defaultStyleFormat, defaultEventFormat
+	hasEvents := false
+
+	for i, line := range script.lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			section = strings.ToLower(trimmed)
+			if section == "[events]" {
+				hasEvents = true
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimLeft(value, " ")
+
+		switch {
+		case strings.EqualFold(key, "Format") && (section == "[v4+ styles]" || section == "[v4 styles]"):
+			styleFormat = formatFields(value)
+		case strings.EqualFold(key, "Style") && (section == "[v4+ styles]" || section == "[v4 styles]"):
+			fields := strings.SplitN(value, ",", len(styleFormat))
+			name, font := fieldIndex(styleFormat, "name"), fieldIndex(styleFormat, "fontname")
+			if name < 0 || font < 0 || max(name, font) >= len(fields) {
+				continue
+			}
+			script.styles = append(script.styles, assStyle{
+				line: i, key: key, name: strings.TrimSpace(fields[name]), fields: fields, font: font,
+			})
+		case strings.EqualFold(key, "Format") && section == "[events]":
+			eventFormat = formatFields(value)
+		case strings.EqualFold(key, "Dialogue") && section == "[events]":
+			ev, cue, ok, err := parseDialogue(trimmed, value, eventFormat)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			if !ok {
+				continue
+			}
+			ev.line = i
+			cue.Index = len(sub.Cues) + 1
+			script.events = append(script.events, ev)
+			sub.Cues = append(sub.Cues, cue)
+		}
+	}
+
+	if !hasEvents || fieldIndex(eventFormat, "text") != len(eventFormat)-1 {
+		return nil, ErrNotASS
+	}
+
+	sub.ass = script
+	return sub, nil
+}
+
+// parseDialogue splits a Dialogue line into its untranslated part and a cue.
+// ok is false for events that are kept as they are.
+func parseDialogue(line, value string, format []string) (assEvent, Cue, bool, error) {
+	fields := strings.SplitN(value, ",", len(format))
+	if len(fields) != len(format) {
+		return assEvent{}, Cue{}, false, fmt.Errorf("dialogue has %d fields, format has %d", len(fields), len(format))
+	}
+
+	startField, endField := fieldIndex(format, "start"), fieldIndex(format, "end")
+	if startField < 0 || endField < 0 {
+		return assEvent{}, Cue{}, false, fmt.Errorf("%w: event format has no start or end", ErrNotASS)
+	}
+	start, err := parseASSTimestamp(fields[startField])
+	if err != nil {
+		return assEvent{}, Cue{}, false, err
+	}
+	end, err := parseASSTimestamp(fields[endField])
+	if err != nil {
+		return assEvent{}, Cue{}, false, err
+	}
+
+	var style string
+	if i := fieldIndex(format, "style"); i >= 0 {
+		style = strings.TrimSpace(fields[i])
+	}
+
+	text := fields[len(fields)-1]
+	if assKaraokeTag.MatchString(text) || assDrawingTag.MatchString(text) ||
+		assSignTag.MatchString(text) || assSignStyle.MatchString(style) {
+		return assEvent{}, Cue{}, false, nil
+	}
+
+	// Toggles that open in the leading blocks usually close later in the
+	// text, so they stay with the text
+	prefix := assBlockPrefix.FindString(text)
+	if loc := assToggleBlock.FindStringIndex(prefix); loc != nil {
+		prefix = prefix[:loc[0]]
+	}
+	body := assToSRT(text[len(prefix):])
+	if strings.TrimSpace(tagPattern.ReplaceAllString(body, "")) == "" {
+		return assEvent{}, Cue{}, false, nil
+	}
+
+	ev := assEvent{head: line[:len(line)-len(text)], prefix: prefix, style: style}
+	return ev, Cue{Start: start, End: end, Lines: strings.Split(body, "\n")}, true, nil
+}
+
+func formatFields(value string) []string {
+	fields := strings.Split(value, ",")
+	for i, f := range fields {
+		fields[i] = strings.ToLower(strings.TrimSpace(f))
+	}
+	return fields
+}
+
+func fieldIndex(format []string, name string) int {
+	for i, f := range format {
+		if f == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func parseASSTimestamp(s string) (time.Duration, error) {
+	m := assTimestampPat.FindStringSubmatch(s)
+	if m == nil {
+		return 0, fmt.Errorf("invalid timestamp %q", strings.TrimSpace(s))
+	}
+	h, _ := strconv.Atoi(m[1])
+	mins, _ := strconv.Atoi(m[2])
+	sec, _ := strconv.Atoi(m[3])
+	// Centiseconds, tolerating milliseconds: ".5" is 500ms, ".05" 50ms
+	ms, _ := strconv.Atoi((m[4] + "00")[:3])
+	return time.Duration(h)*time.Hour + time.Duration(mins)*time.Minute +
+		time.Duration(sec)*time.Second + time.Duration(ms)*time.Millisecond, nil
+}
+
+// FormatASSTimestamp formats d as an ASS timestamp (H:MM:SS.cc).
+func FormatASSTimestamp(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	cs := d / (10 * time.Millisecond)
+	return fmt.Sprintf("%d:%02d:%02d.%02d", cs/360000, cs/6000%60, cs/100%60, cs%100)
+}
+
+// WriteASS writes sub as an ASS script. A subtitle parsed from ASS is written
+// back with its own script around the cue text; any other subtitle gets a
+// single default style. SRT formatting tags are converted to override tags.
+// sub.Font, if set, replaces the font of every style used by the cues.
+func WriteASS(w io.Writer, sub *Subtitle) error {
+	var buf bytes.Buffer
+	if sub.BOM {
+		buf.Write(utf8BOM)
+	}
+
+	if s := sub.ass; s != nil && len(s.events) == len(sub.Cues) {
+		s.write(&buf, sub)
+	} else {
+		font := sub.Font
+		if font == "" {
+			font = defaultASSFont
+		}
+		fmt.Fprintf(&buf, assHeader, font)
+		for _, c := range sub.Cues {
+			fmt.Fprintf(&buf, "Dialogue: 0,%s,%s,Default,,0,0,0,,%s\n",
+				FormatASSTimestamp(c.Start), FormatASSTimestamp(c.End), srtToASS(c.Text()))
+		}
+	}
+
+	out := buf.Bytes()
+	if sub.CRLF {
+		out = bytes.ReplaceAll(out, []byte("\n"), []byte("\r\n"))
+	}
+	_, err := w.Write(out)
+	return err
+}
+
+// write reproduces the script with the text of sub's cues.
+func (s *assScript) write(buf *bytes.Buffer, sub *Subtitle) {
+	lines := make([]string, len(s.lines))
+	copy(lines, s.lines)
+
+	used := make(map[string]bool)
+	for i, ev := range s.events {
+		lines[ev.line] = ev.head + ev.prefix + srtToASS(sub.Cues[i].Text())
+		used[strings.ToLower(ev.style)] = true
+	}
+
+	if sub.Font != "" {
+		for _, st := range s.styles {
+			if !used[strings.ToLower(st.name)] {
+				continue
+			}
+			fields := make([]string, len(st.fields))
+			copy(fields, st.fields)
+			fields[st.font] = sub.Font
+			lines[st.line] = st.key + ": " + strings.Join(fields, ",")
+		}
+	}
+
+	for i, l := range lines {
+		if s.dropped[i] {
+			continue
+		}
+		buf.WriteString(l)
+		buf.WriteByte('\n')
+	}
+}
+
+var (
+	srtTagPattern   = regexp.MustCompile(`(?i)<(/?)([bius])>|<font\s+([^>]*)>|</font>|<[^>]*>`)
+	fontAttrPattern = regexp.MustCompile(`(?i)(color|size)\s*=\s*"?([^"\s>]+)"?`)
+)
+
+// srtToASS converts SRT cue text to an ASS dialogue text: <i>, <b>, <u>, <s>
+// and <font color/size> become override tags, other HTML tags are dropped and
+// line breaks become \N. ASS tags such as {\an8} are kept.
+func srtToASS(text string) string {
+	text = srtTagPattern.ReplaceAllStringFunc(text, func(tag string) string {
+		m := srtTagPattern.FindStringSubmatch(tag)
+		switch {
+		case m[2] != "":
+			state := "1"
+			if m[1] == "/" {
+				state = "0"
+			}
+			return `{\` + strings.ToLower(m[2]) + state + `}`
+		case strings.EqualFold(tag, "</font>"):
+			return `{\c\fs}`
+		case m[3] != "":
+			var overrides strings.Builder
+			for _, attr := range fontAttrPattern.FindAllStringSubmatch(m[3], -1) {
+				switch strings.ToLower(attr[1]) {
+				case "color":
+					if c, ok := assColor(attr[2]); ok {
+						overrides.WriteString(`\c` + c)
+					}
+				case "size":
+					overrides.WriteString(`\fs` + attr[2])
+				}
+			}
+			if overrides.Len() == 0 {
+				return ""
+			}
+			return "{" + overrides.String() + "}"
+		}
+		return ""
+	})
+	return strings.ReplaceAll(text, "\n", `\N`)
+}
+
+// assToSRT converts ASS dialogue text to cue text: \N hard breaks become line
+// breaks, \n soft breaks (a space unless the script wraps with style 2)
+// become spaces, and single bold, italic, underline and strikeout toggles
+// become SRT tags, which models keep reliably. Other override blocks stay in
+// place.
+func assToSRT(text string) string {
+	text = strings.NewReplacer(`\N`, "\n", `\n`, " ").Replace(text)
+	return assToggleBlock.ReplaceAllStringFunc(text, func(block string) string {
+		m := assToggleBlock.FindStringSubmatch(block)
+		if m[2] == "1" {
+			return "<" + m[1] + ">"
+		}
+		return "</" + m[1] + ">"
+	})
+}
+
+// assColor converts "#RRGGBB" to the ASS "&HBBGGRR&" notation.
+func assColor(hex string) (string, bool) {
+	hex = strings.TrimPrefix(hex, "#")
+	if len(hex) != 6 {
+		return "", false
+	}
+	return "&H" + strings.ToUpper(hex[4:6]+hex[2:4]+hex[0:2]) + "&", true
+}
@@ -0,0 +1,191 @@
+package subtitle
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const assEventsHeader = "[Script Info]\nScriptType: v4.00+\n\n" +
+	"[V4+ Styles]\nFormat: Name, Fontname, Fontsize\n" +
+	"Style: Default,Arial,20\nStyle: Signs,Arial,18\nStyle: Song,Verdana,20\n\n" +
+	"[Events]\nFormat: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n"
+
+func dialogue(style, text string) string {
+	return "Dialogue: 0,0:00:01.00,0:00:02.50," + style + ",,0,0,0,," + text + "\n"
+}
+
+func TestParseASS(t *testing.T) {
+	tests := []struct {
+		name  string
+		event string
+		want  []string // Cue lines; nil when the event is not translated
+	}{
+		{"plain", dialogue("Default", "Hello."), []string{"Hello."}},
+		{"hard break", dialogue("Default", `One\NTwo`), []string{"One", "Two"}},
+		{"soft break is a space", dialogue("Default", `One\ntwo`), []string{"One two"}},
+		{"toggles become tags", dialogue("Default", `{\i1}Hi{\i0} {\b1}there{\b0}`), []string{"<i>Hi</i> <b>there</b>"}},
+		{"leading blocks kept aside", dialogue("Default", `{\an8\fad(200,200)}Top`), []string{"Top"}},
+		{"other overrides stay", dialogue("Default", `Big {\fs30}word`), []string{`Big {\fs30}word`}},
+		{"karaoke", dialogue("Song", `{\k20}La {\k30}la`), nil},
+		{"drawing", dialogue("Default", `{\p1}m 0 0 l 10 10{\p0}`), nil},
+		{"positioned", dialogue("Default", `{\pos(100,200)}EXIT`), nil},
+		{"moving", dialogue("Default", `{\move(0,0,100,100)}Road`), nil},
+		{"sign style", dialogue("Signs", "Bakery"), nil},
+		{"title style", dialogue("Episode Title", "Chapter One"), nil},
+		{"style merely containing sign", dialogue("Design", "Hello."), []string{"Hello."}},
+		{"empty text", dialogue("Default", `{\an8}`), nil},
+		{"comment", "Comment: 0,0:00:01.00,0:00:02.50,Default,,0,0,0,,Note\n", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, err := ParseASS([]byte(assEventsHeader + tt.event))
+			if err != nil {
+				t.Fatalf("ParseASS: %v", err)
+			}
+			if tt.want == nil {
+				if len(sub.Cues) != 0 {
+					t.Fatalf("event translated: %+v", sub.Cues)
+				}
+				return
+			}
+			assertCues(t, sub.Cues, []Cue{{Index: 1, Start: ms(1000), End: ms(2500), Lines: tt.want}})
+		})
+	}
+}
+
+func TestParseASSErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want error
+	}{
+		{"no events section", "[Script Info]\nTitle: x\n", ErrNotASS},
+		{"text not last", "[Events]\nFormat: Layer, Start, End, Text, Style\n", ErrNotASS},
+		{"bad timestamp", assEventsHeader + "Dialogue: 0,1.00,0:00:02.00,Default,,0,0,0,,Hi\n", nil},
+		{"missing fields", assEventsHeader + "Dialogue: 0,0:00:01.00,0:00:02.00\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseASS([]byte(tt.in))
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestASSRoundTrip(t *testing.T) {
+	script := assEventsHeader +
+		"Comment: 0,0:00:00.00,0:00:01.00,Default,,0,0,0,,Typeset by someone\n" +
+		dialogue("Default", `{\an8\fad(200,200)}{\i1}Hello{\i0}\Nthere`) +
+		dialogue("Signs", `{\pos(10,10)}Bakery`) +
+		dialogue("Song", `{\k20}La {\k30}la`) +
+		dialogue("Default", `Big {\fs30}word`) +
+		"\n[Fonts]\nfontname: custom.ttf\n"
+
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"LF", script},
+		{"BOM and CRLF", "\ufeff" + strings.ReplaceAll(script, "\n", "\r\n")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, err := ParseASS([]byte(tt.in))
+			if err != nil {
+				t.Fatalf("ParseASS: %v", err)
+			}
+			if len(sub.Cues) != 2 {
+				t.Fatalf("got %d cues, want 2", len(sub.Cues))
+			}
+			var b bytes.Buffer
+			if err := WriteASS(&b, sub); err != nil {
+				t.Fatalf("WriteASS: %v", err)
+			}
+			if b.String() != tt.in {
+				t.Errorf("round trip =\n%s\nwant\n%s", b.String(), tt.in)
+			}
+		})
+	}
+}
+
+func TestWriteASSTranslated(t *testing.T) {
+	src := assEventsHeader +
+		dialogue("Default", `{\an8}Hello`) +
+		dialogue("Signs", `{\pos(10,10)}Bakery`)
+	sub, err := ParseASS([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := sub.Clone()
+	out.Cues[0].Lines = []string{"<i>Hallo</i>", "Welt"}
+	out.Font = "Noto Sans CJK SC"
+
+	var b bytes.Buffer
+	if err := WriteASS(&b, out); err != nil {
+		t.Fatal(err)
+	}
+	got := b.String()
+	for _, want := range []string{
+		dialogue("Default", `{\an8}{\i1}Hallo{\i0}\NWelt`),
+		dialogue("Signs", `{\pos(10,10)}Bakery`),
+		"Style: Default,Noto Sans CJK SC,20\n",
+		"Style: Signs,Arial,18\n", // Not used by a translated event
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output lacks %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestWriteASSConverted(t *testing.T) {
+	sub := &Subtitle{Cues: []Cue{
+		{Index: 1, Start: ms(1000), End: ms(2000), Lines: []string{`<font color="#FF8000" size="20">Hi</font>`, "<b>there</b>"}},
+	}}
+
+	var b bytes.Buffer
+	if err := WriteASS(&b, sub); err != nil {
+		t.Fatal(err)
+	}
+	got := b.String()
+	for _, want := range []string{
+		"Style: Default,Arial,16,",
+		`Dialogue: 0,0:00:01.00,0:00:02.00,Default,,0,0,0,,{\c&H0080FF&\fs20}Hi{\c\fs}\N{\b1}there{\b0}` + "\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output lacks %q:\n%s", want, got)
+		}
+	}
+
+	// The converted script reads back with the leading colour block set aside
+	back, err := ParseASS(b.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertCues(t, back.Cues, []Cue{{Index: 1, Start: ms(1000), End: ms(2000), Lines: []string{`Hi{\c\fs}`, "<b>there</b>"}}})
+}
+
+func TestFormatASSTimestamp(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want string
+	}{
+		{0, "0:00:00.00"},
+		{-time.Second, "0:00:00.00"},
+		{ms(1234), "0:00:01.23"},
+		{2*time.Hour + 3*time.Minute + ms(4560), "2:03:04.56"},
+	}
+	for _, tt := range tests {
+		if got := FormatASSTimestamp(tt.in); got != tt.want {
+			t.Errorf("FormatASSTimestamp(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
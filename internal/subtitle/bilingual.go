@@ -0,0 +1,160 @@
+package subtitle
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BilingualStyle is the inline styling of one language in a bilingual cue.
+// Zero values keep the player's defaults.
+type BilingualStyle struct {
+	Color  string // "#RRGGBB"
+	Size   int    // Font size
+	Italic bool
+}
+
+// BilingualOptions controls how source and translation are stacked.
+type BilingualOptions struct {
+	SourceFirst      bool // Source above the translation (default: translation on top)
+	SourceStyle      BilingualStyle
+	TranslationStyle BilingualStyle
+	VTT              bool // Style with WebVTT classes instead of SRT font tags
+}
+
+// Class names of the spans around each language in WebVTT bilingual cues.
+const (
+	vttSourceClass      = "source"
+	vttTranslationClass = "translation"
+)
+
+// Bilingual returns a copy of out in which every cue holds the translation
+// and the source text of the same cue, styled with SRT font tags, or for
+// WebVTT with class spans and a STYLE block. src and out must be aligned (see
+// CheckAligned).
+func Bilingual(src, out *Subtitle, opts BilingualOptions) (*Subtitle, error) {
+	if err := CheckAligned(src, out); err != nil {
+		return nil, err
+	}
+
+	style := func(lines []string, st BilingualStyle, class string) []string {
+		if opts.VTT {
+			return spanLines(lines, class)
+		}
+		return styleLines(lines, st)
+	}
+
+	bi := out.Clone()
+	for i, c := range out.Cues {
+		source := style(src.Cues[i].Lines, opts.SourceStyle, vttSourceClass)
+		translation := style(c.Lines, opts.TranslationStyle, vttTranslationClass)
+		if src.Cues[i].IsEmpty() || src.Cues[i].Text() == c.Text() {
+			// Nothing to pair (e.g. music notes left as-is): show it once
+			bi.Cues[i].Lines = translation
+			continue
+		}
+
+		if opts.SourceFirst {
+			bi.Cues[i].Lines = append(source, translation...)
+		} else {
+			bi.Cues[i].Lines = append(translation, source...)
+		}
+	}
+
+	if opts.VTT {
+		var rules []string
+		rules = append(rules, cueRules(vttSourceClass, opts.SourceStyle)...)
+		rules = append(rules, cueRules(vttTranslationClass, opts.TranslationStyle)...)
+		bi.vtt = bi.vttWithStyle(rules)
+	}
+	return bi, nil
+}
+
+// nonEmptyLines returns lines without blank ones.
+func nonEmptyLines(lines []string) []string {
+	out := make([]string, 0, len(lines))
+	for _, l := range lines {
+		if strings.TrimSpace(l) != "" {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// styleLines wraps lines in <font> and <i> tags for style.
+func styleLines(lines []string, style BilingualStyle) []string {
+	styled := nonEmptyLines(lines)
+	if len(styled) == 0 {
+		return styled
+	}
+
+	var open, closing string
+	if style.Italic {
+		open, closing = "<i>", "</i>"
+	}
+	var attrs []string
+	if style.Color != "" {
+		attrs = append(attrs, fmt.Sprintf(`color="%s"`, style.Color))
+	}
+	if style.Size > 0 {
+		attrs = append(attrs, fmt.Sprintf(`size="%d"`, style.Size))
+	}
+	if len(attrs) > 0 {
+		open = "<font " + strings.Join(attrs, " ") + ">" + open
+		closing += "</font>"
+	}
+
+	styled[0] = open + styled[0]
+	styled[len(styled)-1] += closing
+	return styled
+}
+
+// spanLines wraps lines in a WebVTT class span; its style comes from the
+// STYLE block (see cueRules).
+func spanLines(lines []string, class string) []string {
+	spanned := nonEmptyLines(lines)
+	if len(spanned) == 0 {
+		return spanned
+	}
+	spanned[0] = "<c." + class + ">" + spanned[0]
+	spanned[len(spanned)-1] += "</c>"
+	return spanned
+}
+
+// cueRules returns the ::cue() CSS rule for class, or nothing for the
+// default style.
+func cueRules(class string, style BilingualStyle) []string {
+	var props []string
+	if style.Color != "" {
+		props = append(props, "  color: "+style.Color+";")
+	}
+	if style.Size > 0 {
+		props = append(props, fmt.Sprintf("  font-size: %dpx;", style.Size))
+	}
+	if style.Italic {
+		props = append(props, "  font-style: italic;")
+	}
+	if len(props) == 0 {
+		return nil
+	}
+	rules := append([]string{"::cue(." + class + ") {"}, props...)
+	return append(rules, "}")
+}
+
+// vttWithStyle returns a copy of the WebVTT script of s, or a new one, with a
+// STYLE block of rules ahead of all other blocks.
+func (s *Subtitle) vttWithStyle(rules []string) *vttScript {
+	out := &vttScript{header: []string{"WEBVTT"}, cues: make([]vttCue, len(s.Cues))}
+	if s.vtt != nil && len(s.vtt.cues) == len(s.Cues) {
+		out.header, out.cues = s.vtt.header, s.vtt.cues
+		out.blocks = append(out.blocks, s.vtt.blocks...)
+	} else {
+		for i := range s.Cues {
+			out.blocks = append(out.blocks, vttBlock{cue: i})
+		}
+	}
+	if len(rules) > 0 {
+		style := append([]string{"STYLE"}, rules...)
+		out.blocks = append([]vttBlock{{raw: style}}, out.blocks...)
+	}
+	return out
+}
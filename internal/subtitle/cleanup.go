@@ -0,0 +1,291 @@
+package subtitle
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CleanupRules selects the pre-processing applied before translation. The
+// zero value changes nothing.
+type CleanupRules struct {
+	StripHearingImpaired bool     // Drop [DOOR CREAKS], (sighs) and speaker labels such as "JOHN:"
+	StripMusic           bool     // Drop lyrics and music lines marked with ♪, ♫ or #...#
+	RemoveCredits        bool     // Drop "Subtitles by ..." style credit and ad cues
+	CreditPatterns       []string // Extra case-insensitive regexes of credit cues
+	MergeSentences       bool     // Join a sentence split across consecutive cues (not for ASS)
+	NormalizeWhitespace  bool     // Collapse spaces, drop invisible characters and blank lines
+}
+
+// Enabled reports whether any rule is switched on.
+func (r CleanupRules) Enabled() bool {
+	return r.StripHearingImpaired || r.StripMusic || r.RemoveCredits || r.MergeSentences || r.NormalizeWhitespace
+}
+
+// CleanupStats counts what a cleanup changed.
+type CleanupStats struct {
+	Edited  int // Cues whose text changed
+	Removed int // Cues dropped because nothing was left
+	Merged  int // Cues joined into the cue before them
+}
+
+// Changed reports whether the cleanup changed anything.
+func (s CleanupStats) Changed() bool {
+	return s.Edited+s.Removed+s.Merged > 0
+}
+
+func (s CleanupStats) String() string {
+	return fmt.Sprintf("%d edited, %d removed, %d merged", s.Edited, s.Removed, s.Merged)
+}
+
+const (
+	maxMergeGap      = time.Second
+	maxMergeDuration = 10 * time.Second
+	maxMergeLines    = 3
+)
+
+var (
+	defaultCreditPatterns = []string{
+		`\b(subtitles?|subs|captions?|captioned|captioning|transcripts?|transcribed|synced|sync|resynced|corrected|corrections|ripped|encoded|translated|translation|timing)\b.{0,40}\bby\b`,
+		`opensubtitles|addic7ed|subscene|podnapisi|tvsubtitles`,
+		`https?://|www\.\S+`,
+		`advertise your product|become (a )?vip member`,
+	}
+
+	hiBracketPattern   = regexp.MustCompile(`\[[^\]]*\]|\([^)]*\)`)
+	hiSpeakerPattern   = regexp.MustCompile(`^((?:<[^>]*>)*)(-\s*)?([A-Z][A-Z0-9 .'&#-]*[A-Z0-9.]):\s*([^\s\p{Ll}]|$)`)
+	musicLinePattern   = regexp.MustCompile(`^[♪♫]|[♪♫]$|^#.*#$`)
+	invisiblePattern   = regexp.MustCompile(`[\x{200b}-\x{200d}\x{2060}\x{feff}\x{00ad}]`)
+	spacePattern       = regexp.MustCompile(`[ \t\x{00a0}\x{2000}-\x{200a}\x{202f}\x{3000}]+`)
+	sentenceEndPattern = regexp.MustCompile(`[.!?…"'”’»」』)\]♪♫:;]$`)
+)
+
+// cueGroup is one cue of a cleaned subtitle: the source cues it covers, in
+// order, and its text.
+type cueGroup struct {
+	from  []int
+	lines []string
+}
+
+// Cleanup returns a copy of sub with rules applied. Cues left without text
+// are dropped and merged cues span the timing of the cues they replace, so
+// the result, not sub, is what a translation must be aligned with. Cues that
+// were already empty are kept.
+func Cleanup(sub *Subtitle, rules CleanupRules) (*Subtitle, CleanupStats, error) {
+	var stats CleanupStats
+	if !rules.Enabled() {
+		return sub, stats, nil
+	}
+
+	var credits []*regexp.Regexp
+	if rules.RemoveCredits {
+		for _, p := range append(append([]string{}, defaultCreditPatterns...), rules.CreditPatterns...) {
+			re, err := regexp.Compile("(?i)" + p)
+			if err != nil {
+				return nil, stats, fmt.Errorf("credit pattern %q: %w", p, err)
+			}
+			credits = append(credits, re)
+		}
+	}
+
+	groups := make([]cueGroup, 0, len(sub.Cues))
+	for i, c := range sub.Cues {
+		if c.IsEmpty() {
+			groups = append(groups, cueGroup{from: []int{i}, lines: c.Lines})
+			continue
+		}
+
+		lines := cleanLines(c.Lines, rules)
+		text := plainText(strings.Join(lines, "\n"))
+		if text != "" && matchesAny(credits, text) {
+			text = ""
+		}
+		if text == "" {
+			stats.Removed++
+			continue
+		}
+		if strings.Join(lines, "\n") != c.Text() {
+			stats.Edited++
+		}
+		groups = append(groups, cueGroup{from: []int{i}, lines: lines})
+	}
+
+	if rules.MergeSentences && sub.ass == nil {
+		groups = mergeSentences(sub, groups, &stats)
+	}
+
+	if !stats.Changed() {
+		return sub, stats, nil
+	}
+	return sub.regroup(groups), stats, nil
+}
+
+// notSpeakers are all-caps words that end in a colon in shouted or emphatic
+// dialogue far more often than they name a speaker.
+var notSpeakers = map[string]bool{
+	"OK": true, "OKAY": true, "YES": true, "NO": true, "HEY": true, "HI": true,
+	"OH": true, "AH": true, "UH": true, "WHAT": true, "WAIT": true, "STOP": true,
+	"NOW": true, "LOOK": true, "LISTEN": true, "NOTE": true, "WARNING": true,
+	"PS": true, "P.S.": true, "TV": true, "FYI": true,
+}
+
+// stripSpeaker removes a leading speaker label. A label has two or more
+// capitals, is not a common all-caps word, and is followed by speech that does
+// not start in lower case: "JOHN: Let's go" loses its label, "OK: Let's go"
+// and "OK: let's go" are dialogue.
+func stripSpeaker(l string) string {
+	m := hiSpeakerPattern.FindStringSubmatch(l)
+	if m == nil || notSpeakers[m[3]] {
+		return l
+	}
+	return m[1] + m[2] + m[4] + l[len(m[0]):]
+}
+
+// cleanLines applies the line-level rules and drops lines left empty.
+func cleanLines(lines []string, rules CleanupRules) []string {
+	out := make([]string, 0, len(lines))
+	for _, l := range lines {
+		if rules.NormalizeWhitespace {
+			l = invisiblePattern.ReplaceAllString(l, "")
+			l = strings.TrimSpace(spacePattern.ReplaceAllString(l, " "))
+		}
+		if rules.StripMusic && musicLinePattern.MatchString(plainText(l)) {
+			continue
+		}
+		if rules.StripHearingImpaired {
+			l = hiBracketPattern.ReplaceAllString(l, "")
+			l = stripSpeaker(l)
+			l = strings.TrimSpace(spacePattern.ReplaceAllString(l, " "))
+			if t := plainText(l); t == "" || t == "-" {
+				continue
+			}
+		}
+		if rules.NormalizeWhitespace && plainText(l) == "" {
+			continue
+		}
+		out = append(out, l)
+	}
+
+	// A dialogue dash is pointless once the other speaker's line is gone
+	if rules.StripHearingImpaired && len(out) == 1 && len(lines) > 1 {
+		out[0] = strings.TrimPrefix(strings.TrimPrefix(out[0], "-"), " ")
+	}
+	return out
+}
+
+// mergeSentences joins each group with the following ones while its text
+// does not end a sentence and the next cue continues it soon after.
+func mergeSentences(sub *Subtitle, groups []cueGroup, stats *CleanupStats) []cueGroup {
+	merged := make([]cueGroup, 0, len(groups))
+	for _, g := range groups {
+		if len(merged) > 0 && continues(sub, merged[len(merged)-1], g) {
+			prev := &merged[len(merged)-1]
+			last := len(prev.lines) - 1
+			prev.lines[last] = strings.TrimSuffix(strings.TrimRight(prev.lines[last], " "), "...")
+			next := append([]string{}, g.lines...)
+			next[0] = strings.TrimLeft(strings.TrimPrefix(next[0], "..."), " ")
+			prev.lines = append(prev.lines, next...)
+			prev.from = append(prev.from, g.from...)
+			stats.Merged++
+			continue
+		}
+		g.lines = append([]string{}, g.lines...)
+		merged = append(merged, g)
+	}
+	return merged
+}
+
+func continues(sub *Subtitle, prev, next cueGroup) bool {
+	if len(prev.lines) == 0 || len(next.lines) == 0 || len(prev.lines)+len(next.lines) > maxMergeLines {
+		return false
+	}
+	first, last := sub.Cues[prev.from[0]], sub.Cues[prev.from[len(prev.from)-1]]
+	following := sub.Cues[next.from[0]]
+	if following.Start-last.End > maxMergeGap || following.End-first.Start > maxMergeDuration {
+		return false
+	}
+
+	end := plainText(prev.lines[len(prev.lines)-1])
+	start := plainText(next.lines[0])
+	if strings.HasSuffix(end, "...") && strings.HasPrefix(start, "...") {
+		return true
+	}
+	if end == "" || sentenceEndPattern.MatchString(end) || strings.HasPrefix(start, "-") {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(start)
+	return unicode.IsLower(r)
+}
+
+// plainText returns s without formatting tags and surrounding whitespace.
+func plainText(s string) string {
+	return strings.TrimSpace(tagPattern.ReplaceAllString(s, ""))
+}
+
+func matchesAny(patterns []*regexp.Regexp, s string) bool {
+	for _, re := range patterns {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// regroup builds the subtitle made of groups, keeping the script of the
+// source format for the cues that remain.
+func (s *Subtitle) regroup(groups []cueGroup) *Subtitle {
+	out := &Subtitle{BOM: s.BOM, CRLF: s.CRLF, Font: s.Font, Cues: make([]Cue, len(groups))}
+	for i, g := range groups {
+		first, last := s.Cues[g.from[0]], s.Cues[g.from[len(g.from)-1]]
+		out.Cues[i] = Cue{Index: i + 1, Start: first.Start, End: last.End, Lines: g.lines}
+	}
+	if s.ass != nil {
+		out.ass = s.ass.regroup(groups)
+	}
+	if s.vtt != nil {
+		out.vtt = s.vtt.regroup(groups)
+	}
+	return out
+}
+
+// regroup keeps the events of the first cue of each group and drops the
+// Dialogue lines of all other cues.
+func (s *assScript) regroup(groups []cueGroup) *assScript {
+	out := &assScript{lines: s.lines, styles: s.styles, dropped: make(map[int]bool)}
+	for k, v := range s.dropped {
+		out.dropped[k] = v
+	}
+	for _, ev := range s.events {
+		out.dropped[ev.line] = true
+	}
+	for _, g := range groups {
+		ev := s.events[g.from[0]]
+		out.events = append(out.events, ev)
+		delete(out.dropped, ev.line)
+	}
+	return out
+}
+
+// regroup keeps the blocks that are not cues and one cue block per group,
+// with the identifier and settings of the group's first cue.
+func (s *vttScript) regroup(groups []cueGroup) *vttScript {
+	first := make(map[int]int, len(groups))
+	out := &vttScript{header: s.header, cues: make([]vttCue, len(groups))}
+	for i, g := range groups {
+		first[g.from[0]] = i
+		out.cues[i] = s.cues[g.from[0]]
+	}
+	for _, b := range s.blocks {
+		if b.raw != nil {
+			out.blocks = append(out.blocks, b)
+			continue
+		}
+		if i, ok := first[b.cue]; ok {
+			out.blocks = append(out.blocks, vttBlock{cue: i})
+		}
+	}
+	return out
+}
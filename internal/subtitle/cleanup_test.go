@@ -0,0 +1,282 @@
+package subtitle
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// srtOf builds an SRT file with one cue per text, two seconds apart.
+func srtOf(texts ...string) string {
+	var b strings.Builder
+	for i, text := range texts {
+		start := time.Duration(i) * 2 * time.Second
+		b.WriteString(strings.Join([]string{
+			strconv.Itoa(i + 1),
+			FormatTimestamp(start) + " --> " + FormatTimestamp(start+1500*time.Millisecond),
+			text,
+			"",
+		}, "\n"))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func cueTexts(sub *Subtitle) []string {
+	texts := make([]string, len(sub.Cues))
+	for i, c := range sub.Cues {
+		texts[i] = c.Text()
+	}
+	return texts
+}
+
+func TestCleanup(t *testing.T) {
+	hi := CleanupRules{StripHearingImpaired: true}
+
+	tests := []struct {
+		name  string
+		cues  []string
+		rules CleanupRules
+		want  []string
+		stats CleanupStats
+	}{
+		{
+			name:  "bracketed sound dropped",
+			cues:  []string{"[DOOR CREAKS]", "Hello (sighs) there."},
+			rules: hi,
+			want:  []string{"Hello there."},
+			stats: CleanupStats{Edited: 1, Removed: 1},
+		},
+		{
+			name:  "speaker label",
+			cues:  []string{"JOHN: Where are you?"},
+			rules: hi,
+			want:  []string{"Where are you?"},
+			stats: CleanupStats{Edited: 1},
+		},
+		{
+			name:  "speaker labels in dialogue",
+			cues:  []string{"- MARY: Hi.\n- DR. JONES: Hello."},
+			rules: hi,
+			want:  []string{"- Hi.\n- Hello."},
+			stats: CleanupStats{Edited: 1},
+		},
+		{
+			name:  "speaker label inside italics",
+			cues:  []string{"<i>NARRATOR: Long ago...</i>"},
+			rules: hi,
+			want:  []string{"<i>Long ago...</i>"},
+			stats: CleanupStats{Edited: 1},
+		},
+		{
+			name:  "speaker label on its own line",
+			cues:  []string{"WOMAN:\nCome in."},
+			rules: hi,
+			want:  []string{"Come in."},
+			stats: CleanupStats{Edited: 1},
+		},
+		{
+			name:  "interjection followed by lower case is dialogue",
+			cues:  []string{"OK: let's go to the U.S.A.: now"},
+			rules: hi,
+			want:  []string{"OK: let's go to the U.S.A.: now"},
+		},
+		{
+			name:  "all-caps interjection is dialogue",
+			cues:  []string{"OK: Let's go.", "- NO: Never!\n- WAIT: Listen."},
+			rules: hi,
+			want:  []string{"OK: Let's go.", "- NO: Never!\n- WAIT: Listen."},
+		},
+		{
+			name:  "single capital is not a label",
+			cues:  []string{"A: That's the answer."},
+			rules: hi,
+			want:  []string{"A: That's the answer."},
+		},
+		{
+			name:  "dash dropped with the other speaker's line",
+			cues:  []string{"- [GASPS]\n- What?"},
+			rules: hi,
+			want:  []string{"What?"},
+			stats: CleanupStats{Edited: 1},
+		},
+		{
+			name:  "music lines",
+			cues:  []string{"♪ La la la ♪", "# Happy birthday #", "Thanks."},
+			rules: CleanupRules{StripMusic: true},
+			want:  []string{"Thanks."},
+			stats: CleanupStats{Removed: 2},
+		},
+		{
+			name:  "credits",
+			cues:  []string{"Subtitles by ACME Team", "Hello.", "Visit www.example.com"},
+			rules: CleanupRules{RemoveCredits: true},
+			want:  []string{"Hello."},
+			stats: CleanupStats{Removed: 2},
+		},
+		{
+			name:  "custom credit pattern",
+			cues:  []string{"Hello.", "Brought to you by NightOwl"},
+			rules: CleanupRules{RemoveCredits: true, CreditPatterns: []string{`nightowl`}},
+			want:  []string{"Hello."},
+			stats: CleanupStats{Removed: 1},
+		},
+		{
+			name:  "split sentence merged",
+			cues:  []string{"I think that", "we should go.", "Now."},
+			rules: CleanupRules{MergeSentences: true},
+			want:  []string{"I think that\nwe should go.", "Now."},
+			stats: CleanupStats{Merged: 1},
+		},
+		{
+			name:  "ellipsis continuation merged",
+			cues:  []string{"Wait...", "...for me."},
+			rules: CleanupRules{MergeSentences: true},
+			want:  []string{"Wait\nfor me."},
+			stats: CleanupStats{Merged: 1},
+		},
+		{
+			name:  "finished sentence not merged",
+			cues:  []string{"Stop.", "right there"},
+			rules: CleanupRules{MergeSentences: true},
+			want:  []string{"Stop.", "right there"},
+		},
+		{
+			name:  "whitespace normalized",
+			cues:  []string{"Hello \u00a0  wor\u200bld  "},
+			rules: CleanupRules{NormalizeWhitespace: true},
+			want:  []string{"Hello world"},
+			stats: CleanupStats{Edited: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, err := ParseSRT([]byte(srtOf(tt.cues...)))
+			if err != nil {
+				t.Fatalf("ParseSRT: %v", err)
+			}
+			got, stats, err := Cleanup(sub, tt.rules)
+			if err != nil {
+				t.Fatalf("Cleanup: %v", err)
+			}
+			if texts := cueTexts(got); strings.Join(texts, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("cues = %q, want %q", texts, tt.want)
+			}
+			if stats != tt.stats {
+				t.Errorf("stats = %+v, want %+v", stats, tt.stats)
+			}
+			for i, c := range got.Cues {
+				if c.Index != i+1 {
+					t.Errorf("cue %d has index %d", i, c.Index)
+				}
+			}
+		})
+	}
+}
+
+func TestCleanupMergedTiming(t *testing.T) {
+	sub, err := ParseSRT([]byte(srtOf("I think that", "we should go.")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _, err := Cleanup(sub, CleanupRules{MergeSentences: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Cues) != 1 {
+		t.Fatalf("got %d cues, want 1", len(got.Cues))
+	}
+	if c := got.Cues[0]; c.Start != sub.Cues[0].Start || c.End != sub.Cues[1].End {
+		t.Errorf("merged cue spans %v-%v, want %v-%v", c.Start, c.End, sub.Cues[0].Start, sub.Cues[1].End)
+	}
+}
+
+func TestCleanupUnchanged(t *testing.T) {
+	sub, err := ParseSRT([]byte(srtOf("Hello.")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rules := range []CleanupRules{{}, {StripHearingImpaired: true, StripMusic: true, NormalizeWhitespace: true}} {
+		got, stats, err := Cleanup(sub, rules)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != sub || stats.Changed() {
+			t.Errorf("rules %+v changed a clean subtitle: %+v", rules, stats)
+		}
+	}
+}
+
+func TestCleanupInvalidCreditPattern(t *testing.T) {
+	sub, err := ParseSRT([]byte(srtOf("Hello.")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := Cleanup(sub, CleanupRules{RemoveCredits: true, CreditPatterns: []string{"("}}); err == nil {
+		t.Error("invalid credit pattern accepted")
+	}
+}
+
+func TestCleanupKeepsScript(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		parse  func([]byte) (*Subtitle, error)
+		write  func(*bytes.Buffer, *Subtitle) error
+		keep   []string
+		drop   []string
+	}{
+		{
+			name: "vtt",
+			source: "WEBVTT\n\nNOTE kept\n\nintro\n00:00:01.000 --> 00:00:02.000 line:90%\n[MUSIC]\n\n" +
+				"main\n00:00:03.000 --> 00:00:04.000 align:start\nJOHN: Hello.\n",
+			parse: ParseVTT,
+			write: func(b *bytes.Buffer, s *Subtitle) error { return WriteVTT(b, s) },
+			keep:  []string{"NOTE kept", "main\n00:00:03.000 --> 00:00:04.000 align:start\nHello."},
+			drop:  []string{"intro", "[MUSIC]", "JOHN"},
+		},
+		{
+			name: "ass",
+			source: "[Script Info]\nScriptType: v4.00+\n\n[V4+ Styles]\n" +
+				"Format: Name, Fontname, Fontsize\nStyle: Default,Arial,20\n\n[Events]\n" +
+				"Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n" +
+				"Dialogue: 0,0:00:01.00,0:00:02.00,Default,,0,0,0,,[MUSIC]\n" +
+				"Dialogue: 0,0:00:03.00,0:00:04.00,Default,,0,0,0,,{\\i1}JOHN: Hello.\n",
+			parse: ParseASS,
+			write: func(b *bytes.Buffer, s *Subtitle) error { return WriteASS(b, s) },
+			keep:  []string{"Style: Default,Arial,20", "0:00:03.00,0:00:04.00,Default,,0,0,0,,{\\i1}Hello."},
+			drop:  []string{"[MUSIC]", "JOHN"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, err := tt.parse([]byte(tt.source))
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			got, _, err := Cleanup(sub, CleanupRules{StripHearingImpaired: true})
+			if err != nil {
+				t.Fatalf("Cleanup: %v", err)
+			}
+			var b bytes.Buffer
+			if err := tt.write(&b, got); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+			out := b.String()
+			for _, s := range tt.keep {
+				if !strings.Contains(out, s) {
+					t.Errorf("output lacks %q:\n%s", s, out)
+				}
+			}
+			for _, s := range tt.drop {
+				if strings.Contains(out, s) {
+					t.Errorf("output still has %q:\n%s", s, out)
+				}
+			}
+		})
+	}
+}
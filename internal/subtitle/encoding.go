@@ -0,0 +1,204 @@
+package subtitle
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// Character sets detected in subtitle files. Only the Unicode ones are
+// written.
+const (
+	CharsetUTF8        = "utf-8"
+	CharsetUTF16LE     = "utf-16le"
+	CharsetUTF16BE     = "utf-16be"
+	CharsetWindows1252 = "windows-1252"
+)
+
+// ErrUnreadable marks a file whose text cannot be decoded, e.g. a binary file
+// or an unsupported encoding. Retrying does not help.
+var ErrUnreadable = errors.New("unreadable subtitle encoding")
+
+var (
+	utf16LEBOM = []byte{0xFF, 0xFE}
+	utf16BEBOM = []byte{0xFE, 0xFF}
+	utf32LEBOM = []byte{0xFF, 0xFE, 0x00, 0x00}
+	utf32BEBOM = []byte{0x00, 0x00, 0xFE, 0xFF}
+)
+
+// Encoding describes how a subtitle file is encoded.
+type Encoding struct {
+	Charset string
+	BOM     bool
+}
+
+func (e Encoding) String() string {
+	if e.BOM {
+		return e.Charset + " with BOM"
+	}
+	return e.Charset
+}
+
+// Decode detects the encoding of data and returns its text as UTF-8. Files
+// with a byte order mark are UTF-8 or UTF-16; without one, valid UTF-8 is
+// taken as is, NUL bytes at every other position mark UTF-16 and anything
+// else is read as Windows-1252. A byte order mark is returned as a UTF-8 BOM,
+// so the parsers record it. The Ctrl-Z end-of-file marker some DOS-era
+// editors append is dropped.
+func Decode(data []byte) ([]byte, Encoding, error) {
+	switch {
+	case bytes.HasPrefix(data, utf32LEBOM), bytes.HasPrefix(data, utf32BEBOM):
+		return nil, Encoding{}, fmt.Errorf("%w: UTF-32 is not supported", ErrUnreadable)
+	case bytes.HasPrefix(data, utf8BOM):
+		enc := Encoding{Charset: CharsetUTF8, BOM: true}
+		if !utf8.Valid(data) {
+			return nil, enc, fmt.Errorf("%w: invalid UTF-8 after a UTF-8 byte order mark", ErrUnreadable)
+		}
+		return checkedText(data, enc)
+	case bytes.HasPrefix(data, utf16LEBOM):
+		return decodeUTF16(data[len(utf16LEBOM):], Encoding{Charset: CharsetUTF16LE, BOM: true})
+	case bytes.HasPrefix(data, utf16BEBOM):
+		return decodeUTF16(data[len(utf16BEBOM):], Encoding{Charset: CharsetUTF16BE, BOM: true})
+	}
+
+	if charset := guessUTF16(data); charset != "" {
+		return decodeUTF16(data, Encoding{Charset: charset})
+	}
+	if utf8.Valid(data) {
+		return checkedText(data, Encoding{Charset: CharsetUTF8})
+	}
+
+	enc := Encoding{Charset: CharsetWindows1252}
+	data, err := checkText(data)
+	if err != nil {
+		return nil, enc, err
+	}
+	for _, b := range data {
+		// Bytes Windows-1252 leaves undefined
+		if b == 0x81 || b == 0x8D || b == 0x8F || b == 0x90 || b == 0x9D {
+			return nil, enc, fmt.Errorf("%w: neither UTF-8 nor Windows-1252 (byte 0x%02X)", ErrUnreadable, b)
+		}
+	}
+	out, err := charmap.Windows1252.NewDecoder().Bytes(data)
+	if err != nil {
+		return nil, enc, fmt.Errorf("%w: %v", ErrUnreadable, err)
+	}
+	return out, enc, nil
+}
+
+func decodeUTF16(data []byte, enc Encoding) ([]byte, Encoding, error) {
+	if len(data)%2 != 0 {
+		return nil, enc, fmt.Errorf("%w: truncated %s", ErrUnreadable, enc.Charset)
+	}
+	out, err := utf16Encoding(enc.Charset).NewDecoder().Bytes(data)
+	if err != nil {
+		return nil, enc, fmt.Errorf("%w: %s: %v", ErrUnreadable, enc.Charset, err)
+	}
+	out, err = checkText(out)
+	if err != nil {
+		return nil, enc, err
+	}
+	if enc.BOM {
+		out = append(append([]byte{}, utf8BOM...), out...)
+	}
+	return out, enc, nil
+}
+
+func utf16Encoding(charset string) encoding.Encoding {
+	endian := unicode.LittleEndian
+	if charset == CharsetUTF16BE {
+		endian = unicode.BigEndian
+	}
+	return unicode.UTF16(endian, unicode.IgnoreBOM)
+}
+
+// guessUTF16 recognizes UTF-16 without a byte order mark by its NUL bytes:
+// the high byte of ASCII characters, which make up most of a subtitle's
+// timing lines.
+func guessUTF16(data []byte) string {
+	sample := data[:min(len(data), 4096)&^1]
+	if len(sample) < 16 {
+		return ""
+	}
+	var even, odd int
+	for i := 0; i < len(sample); i += 2 {
+		if sample[i] == 0 {
+			even++
+		}
+		if sample[i+1] == 0 {
+			odd++
+		}
+	}
+	pairs := len(sample) / 2
+	switch {
+	case odd > pairs*2/5 && even < pairs/10:
+		return CharsetUTF16LE
+	case even > pairs*2/5 && odd < pairs/10:
+		return CharsetUTF16BE
+	}
+	return ""
+}
+
+func checkedText(data []byte, enc Encoding) ([]byte, Encoding, error) {
+	data, err := checkText(data)
+	if err != nil {
+		return nil, enc, err
+	}
+	return data, enc, nil
+}
+
+// dosEOF is the Ctrl-Z that ends files written by DOS-era tools.
+const dosEOF = 0x1A
+
+// checkText drops a trailing DOS end-of-file marker and rejects binary
+// content: any NUL byte, or control characters that make up more than 1% of
+// the text. Legacy files with a stray control character still pass.
+func checkText(data []byte) ([]byte, error) {
+	data = bytes.TrimRight(data, string(rune(dosEOF)))
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		return nil, fmt.Errorf("%w: binary data (NUL at offset %d), not a text file", ErrUnreadable, i)
+	}
+	var control int
+	for _, b := range data {
+		if b < 0x20 && b != '\t' && b != '\n' && b != '\r' && b != '\f' {
+			control++
+		}
+	}
+	if control > 0 && control*100 > len(data) {
+		return nil, fmt.Errorf("%w: binary data (%d control bytes), not a text file", ErrUnreadable, control)
+	}
+	return data, nil
+}
+
+// Encode converts UTF-8 text to enc, adding a byte order mark if enc.BOM is
+// set. A UTF-8 BOM already at the start of text is replaced.
+func Encode(text []byte, enc Encoding) ([]byte, error) {
+	text = bytes.TrimPrefix(text, utf8BOM)
+
+	switch enc.Charset {
+	case "", CharsetUTF8:
+		if enc.BOM {
+			return append(append([]byte{}, utf8BOM...), text...), nil
+		}
+		return text, nil
+	case CharsetUTF16LE, CharsetUTF16BE:
+		out, err := utf16Encoding(enc.Charset).NewEncoder().Bytes(text)
+		if err != nil {
+			return nil, fmt.Errorf("encode %s: %w", enc.Charset, err)
+		}
+		if !enc.BOM {
+			return out, nil
+		}
+		bom := utf16LEBOM
+		if enc.Charset == CharsetUTF16BE {
+			bom = utf16BEBOM
+		}
+		return append(append([]byte{}, bom...), out...), nil
+	}
+	return nil, fmt.Errorf("cannot write %s", enc.Charset)
+}
@@ -0,0 +1,158 @@
+package subtitle
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// utf16 encodes ASCII-only s as UTF-16 in the given byte order.
+func utf16(s string, bigEndian bool) []byte {
+	out := make([]byte, 0, 2*len(s))
+	for _, b := range []byte(s) {
+		if bigEndian {
+			out = append(out, 0, b)
+		} else {
+			out = append(out, b, 0)
+		}
+	}
+	return out
+}
+
+const encodingSample = "1\r\n00:00:01,000 --> 00:00:02,000\r\nHello there.\r\n"
+
+// strayControl has one control character in well over 100 bytes of text.
+var strayControl = strings.Repeat(encodingSample, 2) + "A bell\x07 rings.\r\n"
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []byte
+		want string
+		enc  Encoding
+	}{
+		{"UTF-8", []byte("Grüße"), "Grüße", Encoding{Charset: CharsetUTF8}},
+		{"UTF-8 with BOM", []byte("\ufeffHi"), "\ufeffHi", Encoding{Charset: CharsetUTF8, BOM: true}},
+		{"Windows-1252", []byte("caf\xe9 \x93quoted\x94"), "café “quoted”", Encoding{Charset: CharsetWindows1252}},
+		{
+			"UTF-16LE with BOM",
+			append([]byte{0xFF, 0xFE}, utf16(encodingSample, false)...),
+			"\ufeff" + encodingSample,
+			Encoding{Charset: CharsetUTF16LE, BOM: true},
+		},
+		{
+			"UTF-16BE with BOM",
+			append([]byte{0xFE, 0xFF}, utf16(encodingSample, true)...),
+			"\ufeff" + encodingSample,
+			Encoding{Charset: CharsetUTF16BE, BOM: true},
+		},
+		{"UTF-16LE without BOM", utf16(encodingSample, false), encodingSample, Encoding{Charset: CharsetUTF16LE}},
+		{"UTF-16BE without BOM", utf16(encodingSample, true), encodingSample, Encoding{Charset: CharsetUTF16BE}},
+		{"DOS end-of-file marker", []byte(encodingSample + "\x1a"), encodingSample, Encoding{Charset: CharsetUTF8}},
+		{"DOS end-of-file marker, Windows-1252", []byte("caf\xe9\r\n\x1a"), "café\r\n", Encoding{Charset: CharsetWindows1252}},
+		{"DOS end-of-file marker, UTF-16", utf16(encodingSample+"\x1a", false), encodingSample, Encoding{Charset: CharsetUTF16LE}},
+		{"stray control character", []byte(strayControl), strayControl, Encoding{Charset: CharsetUTF8}},
+		{"empty", nil, "", Encoding{Charset: CharsetUTF8}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, enc, err := Decode(tt.in)
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("text = %q, want %q", got, tt.want)
+			}
+			if enc != tt.enc {
+				t.Errorf("encoding = %v, want %v", enc, tt.enc)
+			}
+		})
+	}
+}
+
+func TestDecodeUnreadable(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []byte
+	}{
+		{"UTF-32LE", []byte{0xFF, 0xFE, 0x00, 0x00, 'H', 0, 0, 0}},
+		{"UTF-32BE", []byte{0x00, 0x00, 0xFE, 0xFF, 0, 0, 0, 'H'}},
+		{"invalid UTF-8 after BOM", []byte("\xef\xbb\xbfcaf\xe9")},
+		{"NUL byte", []byte("Hello\x00world")},
+		{"binary", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")},
+		{"control characters", []byte("\x01\x02\x03\x04text")},
+		{"undefined Windows-1252 byte", []byte("caf\xe9\x81")},
+		{"truncated UTF-16", append([]byte{0xFF, 0xFE}, 'H', 0, 'i')},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := Decode(tt.in); !errors.Is(err, ErrUnreadable) {
+				t.Errorf("err = %v, want %v", err, ErrUnreadable)
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		enc  Encoding
+		want []byte
+	}{
+		{"UTF-8", "Hi", Encoding{Charset: CharsetUTF8}, []byte("Hi")},
+		{"UTF-8 drops BOM", "\ufeffHi", Encoding{Charset: CharsetUTF8}, []byte("Hi")},
+		{"UTF-8 adds BOM", "Hi", Encoding{Charset: CharsetUTF8, BOM: true}, []byte("\ufeffHi")},
+		{"UTF-8 keeps one BOM", "\ufeffHi", Encoding{Charset: CharsetUTF8, BOM: true}, []byte("\ufeffHi")},
+		{"empty charset is UTF-8", "Hi", Encoding{}, []byte("Hi")},
+		{"UTF-16LE", "Hi", Encoding{Charset: CharsetUTF16LE}, []byte{'H', 0, 'i', 0}},
+		{"UTF-16LE with BOM", "\ufeffHi", Encoding{Charset: CharsetUTF16LE, BOM: true}, []byte{0xFF, 0xFE, 'H', 0, 'i', 0}},
+		{"UTF-16BE with BOM", "Hi", Encoding{Charset: CharsetUTF16BE, BOM: true}, []byte{0xFE, 0xFF, 0, 'H', 0, 'i'}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Encode([]byte(tt.text), tt.enc)
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("Encode = % X, want % X", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Encode([]byte("Hi"), Encoding{Charset: CharsetWindows1252}); err == nil {
+		t.Error("Encode wrote Windows-1252")
+	}
+}
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	text := "1\n00:00:01,000 --> 00:00:02,000\nGrüße, 你好 🎬\n"
+	for _, enc := range []Encoding{
+		{Charset: CharsetUTF8},
+		{Charset: CharsetUTF8, BOM: true},
+		{Charset: CharsetUTF16LE},
+		{Charset: CharsetUTF16LE, BOM: true},
+		{Charset: CharsetUTF16BE},
+		{Charset: CharsetUTF16BE, BOM: true},
+	} {
+		t.Run(enc.String(), func(t *testing.T) {
+			data, err := Encode([]byte(text), enc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, detected, err := Decode(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if detected != enc {
+				t.Errorf("detected %v, want %v", detected, enc)
+			}
+			if strings.TrimPrefix(string(got), "\ufeff") != text {
+				t.Errorf("text = %q, want %q", got, text)
+			}
+		})
+	}
+}
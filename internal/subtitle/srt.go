@@ -0,0 +1,197 @@
+package subtitle
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// timingPattern matches "00:00:01,000 --> 00:00:04,000", tolerating "." as the
+// millisecond separator and trailing position coordinates.
+var timingPattern = regexp.MustCompile(`^\s*(\d+):(\d{1,2}):(\d{1,2})[,.](\d{1,3})\s*-->\s*(\d+):(\d{1,2}):(\d{1,2})[,.](\d{1,3})`)
+
+// IsSupported reports whether the subtitle at path can be parsed natively.
+func IsSupported(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".srt") || IsASS(path) || IsVTT(path)
+}
+
+// ReadFile parses the subtitle file at path: ASS/SSA and WebVTT by
+// extension, SRT otherwise. UTF-16 and Windows-1252 files are decoded first
+// (see Decode).
+func ReadFile(path string) (*Subtitle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read subtitle: %w", err)
+	}
+	data, _, err = Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case IsASS(path):
+		return ParseASS(data)
+	case IsVTT(path):
+		return ParseVTT(data)
+	}
+	return ParseSRT(data)
+}
+
+// ParseSRT parses SRT content. Cue indexes are optional; blocks are separated
+// by one or more blank lines.
+func ParseSRT(data []byte) (*Subtitle, error) {
+	sub := &Subtitle{}
+
+	if bytes.HasPrefix(data, utf8BOM) {
+		sub.BOM = true
+		data = data[len(utf8BOM):]
+	}
+
+	text := string(data)
+	if strings.Contains(text, "\r\n") {
+		sub.CRLF = true
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+	}
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	lines := strings.Split(text, "\n")
+	lineNo := 0
+	for lineNo < len(lines) {
+		// Skip blank lines between blocks
+		if strings.TrimSpace(lines[lineNo]) == "" {
+			lineNo++
+			continue
+		}
+
+		cue := Cue{}
+		header := strings.TrimSpace(lines[lineNo])
+		if !timingPattern.MatchString(header) {
+			idx, err := strconv.Atoi(header)
+			if err != nil || lineNo+1 >= len(lines) || !timingPattern.MatchString(lines[lineNo+1]) {
+				// A stray blank line inside a cue splits its text; keep the
+				// remainder with the previous cue instead of failing.
+				if len(sub.Cues) > 0 {
+					last := &sub.Cues[len(sub.Cues)-1]
+					last.Lines = append(last.Lines, strings.TrimRight(lines[lineNo], " \t"))
+					lineNo++
+					continue
+				}
+				return nil, fmt.Errorf("line %d: expected cue index followed by timing, got %q", lineNo+1, header)
+			}
+			cue.Index = idx
+			lineNo++
+		}
+
+		start, end, err := parseTiming(lines[lineNo])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo+1, err)
+		}
+		cue.Start, cue.End = start, end
+		lineNo++
+
+		for lineNo < len(lines) && strings.TrimSpace(lines[lineNo]) != "" {
+			cue.Lines = append(cue.Lines, strings.TrimRight(lines[lineNo], " \t"))
+			lineNo++
+		}
+
+		if cue.Index == 0 {
+			cue.Index = len(sub.Cues) + 1
+		}
+		sub.Cues = append(sub.Cues, cue)
+	}
+
+	return sub, nil
+}
+
+func parseTiming(line string) (start, end time.Duration, err error) {
+	m := timingPattern.FindStringSubmatch(line)
+	if m == nil {
+		return 0, 0, fmt.Errorf("invalid timing line %q", strings.TrimSpace(line))
+	}
+	return timestamp(m[1:5]), timestamp(m[5:9]), nil
+}
+
+func timestamp(parts []string) time.Duration {
+	h, _ := strconv.Atoi(parts[0])
+	m, _ := strconv.Atoi(parts[1])
+	s, _ := strconv.Atoi(parts[2])
+	// Pad short fractions: ",5" means 500ms
+	msStr := (parts[3] + "00")[:3]
+	ms, _ := strconv.Atoi(msStr)
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute +
+		time.Duration(s)*time.Second + time.Duration(ms)*time.Millisecond
+}
+
+// FormatTimestamp formats d as an SRT timestamp (HH:MM:SS,mmm).
+func FormatTimestamp(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	h := d / time.Hour
+	d -= h * time.Hour
+	m := d / time.Minute
+	d -= m * time.Minute
+	s := d / time.Second
+	d -= s * time.Second
+	ms := d / time.Millisecond
+	return fmt.Sprintf("%02d:%02d:%02d,%03d", h, m, s, ms)
+}
+
+// WriteSRT writes sub in SRT format, renumbering cues sequentially and
+// reproducing the source BOM and line endings. WebVTT-only tags such as
+// voices and classes and ASS override blocks of converted sources are
+// dropped.
+func WriteSRT(w io.Writer, sub *Subtitle) error {
+	var buf bytes.Buffer
+	if sub.BOM {
+		buf.Write(utf8BOM)
+	}
+
+	newline := "\n"
+	if sub.CRLF {
+		newline = "\r\n"
+	}
+
+	for i, c := range sub.Cues {
+		if i > 0 {
+			buf.WriteString(newline)
+		}
+		fmt.Fprintf(&buf, "%d%s%s --> %s%s", i+1, newline, FormatTimestamp(c.Start), FormatTimestamp(c.End), newline)
+		for _, line := range c.Lines {
+			line = sub.plainLine(line)
+			buf.WriteString(line)
+			buf.WriteString(newline)
+		}
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// WriteFile writes sub to path in the format of its extension: ASS for .ass
+// and .ssa, WebVTT for .vtt, SRT otherwise.
+func WriteFile(path string, sub *Subtitle) error {
+	write := WriteSRT
+	switch {
+	case IsASS(path):
+		write = WriteASS
+	case IsVTT(path):
+		write = WriteVTT
+	}
+
+	var buf bytes.Buffer
+	if err := write(&buf, sub); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("write subtitle: %w", err)
+	}
+	return nil
+}
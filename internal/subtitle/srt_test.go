@@ -0,0 +1,174 @@
+package subtitle
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func ms(n int) time.Duration { return time.Duration(n) * time.Millisecond }
+
+func TestParseSRT(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []Cue
+		bom  bool
+		crlf bool
+	}{
+		{
+			name: "basic",
+			in:   "1\n00:00:01,000 --> 00:00:02,500\nHello.\n\n2\n00:00:03,000 --> 00:00:04,000\nTwo\nlines\n",
+			want: []Cue{
+				{Index: 1, Start: ms(1000), End: ms(2500), Lines: []string{"Hello."}},
+				{Index: 2, Start: ms(3000), End: ms(4000), Lines: []string{"Two", "lines"}},
+			},
+		},
+		{
+			name: "BOM and CRLF",
+			in:   "\ufeff1\r\n00:00:01,000 --> 00:00:02,000\r\nHi\r\n",
+			want: []Cue{{Index: 1, Start: ms(1000), End: ms(2000), Lines: []string{"Hi"}}},
+			bom:  true,
+			crlf: true,
+		},
+		{
+			name: "missing indexes are numbered",
+			in:   "00:00:01,000 --> 00:00:02,000\nA\n\n00:00:03,000 --> 00:00:04,000\nB\n",
+			want: []Cue{
+				{Index: 1, Start: ms(1000), End: ms(2000), Lines: []string{"A"}},
+				{Index: 2, Start: ms(3000), End: ms(4000), Lines: []string{"B"}},
+			},
+		},
+		{
+			name: "dot separator, short fraction and coordinates",
+			in:   "1\n0:00:01.5 --> 0:00:02.25 X1:10 X2:20\nA\n",
+			want: []Cue{{Index: 1, Start: ms(1500), End: ms(2250), Lines: []string{"A"}}},
+		},
+		{
+			name: "stray blank line inside a cue",
+			in:   "1\n00:00:01,000 --> 00:00:02,000\nHello\n\nworld\n\n2\n00:00:03,000 --> 00:00:04,000\nBye\n",
+			want: []Cue{
+				{Index: 1, Start: ms(1000), End: ms(2000), Lines: []string{"Hello", "world"}},
+				{Index: 2, Start: ms(3000), End: ms(4000), Lines: []string{"Bye"}},
+			},
+		},
+		{
+			name: "extra blank lines and trailing spaces",
+			in:   "\n\n1\n00:00:01,000 --> 00:00:02,000\nHi  \n\n\n\n",
+			want: []Cue{{Index: 1, Start: ms(1000), End: ms(2000), Lines: []string{"Hi"}}},
+		},
+		{
+			name: "empty cue",
+			in:   "1\n00:00:01,000 --> 00:00:02,000\n\n2\n00:00:03,000 --> 00:00:04,000\nB\n",
+			want: []Cue{
+				{Index: 1, Start: ms(1000), End: ms(2000)},
+				{Index: 2, Start: ms(3000), End: ms(4000), Lines: []string{"B"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, err := ParseSRT([]byte(tt.in))
+			if err != nil {
+				t.Fatalf("ParseSRT: %v", err)
+			}
+			if sub.BOM != tt.bom || sub.CRLF != tt.crlf {
+				t.Errorf("BOM, CRLF = %v, %v; want %v, %v", sub.BOM, sub.CRLF, tt.bom, tt.crlf)
+			}
+			assertCues(t, sub.Cues, tt.want)
+		})
+	}
+}
+
+func TestParseSRTErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"text before the first cue", "Hello\n00:00:01,000 --> 00:00:02,000\nA\n"},
+		{"invalid timing", "1\n00:00:01 --> 00:00:02\nA\n"},
+		{"index without timing", "1\nA\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseSRT([]byte(tt.in)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestWriteSRT(t *testing.T) {
+	sub := &Subtitle{Cues: []Cue{
+		{Index: 7, Start: ms(1000), End: ms(2000), Lines: []string{"<i>Hello</i>"}},
+		{Index: 9, Start: time.Hour + ms(61001), End: time.Hour + ms(62000), Lines: []string{"A", "B"}},
+	}}
+	want := "1\n00:00:01,000 --> 00:00:02,000\n<i>Hello</i>\n\n2\n01:01:01,001 --> 01:01:02,000\nA\nB\n"
+
+	var b bytes.Buffer
+	if err := WriteSRT(&b, sub); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != want {
+		t.Errorf("WriteSRT =\n%q\nwant\n%q", b.String(), want)
+	}
+}
+
+func TestSRTRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"LF", "1\n00:00:01,000 --> 00:00:02,000\nHello.\n\n2\n00:00:03,000 --> 00:00:04,000\n<i>Two</i>\nlines\n"},
+		{"BOM and CRLF", "\ufeff1\r\n00:00:01,000 --> 00:00:02,000\r\nHello.\r\n\r\n2\r\n00:00:03,000 --> 00:00:04,000\r\nBye.\r\n"},
+		{"long timestamps", "1\n10:59:59,999 --> 11:00:00,000\nLate.\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, err := ParseSRT([]byte(tt.in))
+			if err != nil {
+				t.Fatalf("ParseSRT: %v", err)
+			}
+			var b bytes.Buffer
+			if err := WriteSRT(&b, sub); err != nil {
+				t.Fatalf("WriteSRT: %v", err)
+			}
+			if b.String() != tt.in {
+				t.Errorf("round trip =\n%q\nwant\n%q", b.String(), tt.in)
+			}
+		})
+	}
+}
+
+func TestFormatTimestamp(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want string
+	}{
+		{0, "00:00:00,000"},
+		{-time.Second, "00:00:00,000"},
+		{ms(1234), "00:00:01,234"},
+		{100*time.Hour + ms(1), "100:00:00,001"},
+	}
+	for _, tt := range tests {
+		if got := FormatTimestamp(tt.in); got != tt.want {
+			t.Errorf("FormatTimestamp(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// assertCues compares cue timing, index and text.
+func assertCues(t *testing.T, got, want []Cue) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d cues, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.Index != w.Index || g.Start != w.Start || g.End != w.End || strings.Join(g.Lines, "\n") != strings.Join(w.Lines, "\n") {
+			t.Errorf("cue %d = %+v, want %+v", i, g, w)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+// Package subtitle parses, validates and writes subtitle files natively so the
+// worker can inspect jobs without relying on the translation scripts.
+package subtitle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	ErrNoCues        = errors.New("subtitle has no cues")
+	ErrCueMismatch   = errors.New("cue count mismatch")
+	ErrTimingChanged = errors.New("cue timing changed")
+	ErrEmptyCue      = errors.New("empty cue")
+)
+
+// Cue is a single timed subtitle entry.
+type Cue struct {
+	Index int
+	Start time.Duration
+	End   time.Duration
+	Lines []string
+}
+
+// Text returns the cue text with lines joined by newlines.
+func (c Cue) Text() string {
+	return strings.Join(c.Lines, "\n")
+}
+
+// IsEmpty reports whether the cue has no visible text.
+func (c Cue) IsEmpty() bool {
+	return strings.TrimSpace(c.Text()) == ""
+}
+
+// Subtitle is a parsed subtitle file. BOM and CRLF record the source layout so
+// it can be reproduced when writing.
+type Subtitle struct {
+	Cues []Cue
+	BOM  bool
+	CRLF bool
+	Font string // Font of the dialogue styles when written as ASS (default: the script's own)
+
+	ass *assScript // Untranslated rest of an ASS/SSA source, nil otherwise
+	vtt *vttScript // Untranslated rest of a WebVTT source, nil otherwise
+}
+
+// Clone returns a copy of s with its own cue slice. The copy keeps the layout
+// and script of s, so it is written the same way with different cue text.
+func (s *Subtitle) Clone() *Subtitle {
+	c := *s
+	c.Cues = make([]Cue, len(s.Cues))
+	copy(c.Cues, s.Cues)
+	return &c
+}
+
+// plainLine drops markup of the source format that SRT cannot carry: ASS
+// override blocks and WebVTT-only tags.
+func (s *Subtitle) plainLine(line string) string {
+	if s.ass != nil {
+		line = assBlockPattern.ReplaceAllString(line, "")
+	}
+	if s.vtt != nil {
+		line = vttOnlyTagPattern.ReplaceAllString(line, "")
+	}
+	return line
+}
+
+// Hash returns a stable content hash of the cues (timing and text),
+// independent of BOM, line endings and cue numbering.
+func (s *Subtitle) Hash() string {
+	h := sha256.New()
+	for _, c := range s.Cues {
+		fmt.Fprintf(h, "%d|%d|%s\x00", c.Start, c.End, c.Text())
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Validate checks that a subtitle is usable as translation input.
+func (s *Subtitle) Validate() error {
+	if len(s.Cues) == 0 {
+		return ErrNoCues
+	}
+
+	nonEmpty := 0
+	for i, c := range s.Cues {
+		if c.End < c.Start {
+			return fmt.Errorf("cue %d: end %s before start %s", i+1, FormatTimestamp(c.End), FormatTimestamp(c.Start))
+		}
+		if !c.IsEmpty() {
+			nonEmpty++
+		}
+	}
+	if nonEmpty == 0 {
+		return fmt.Errorf("%w: all %d cues are empty", ErrNoCues, len(s.Cues))
+	}
+
+	return nil
+}
+
+// CheckAligned verifies that out is a faithful re-timing of src: the same
+// number of cues, identical timestamps, and no cue emptied by translation.
+func CheckAligned(src, out *Subtitle) error {
+	if len(src.Cues) != len(out.Cues) {
+		return fmt.Errorf("%w: source has %d, output has %d", ErrCueMismatch, len(src.Cues), len(out.Cues))
+	}
+
+	for i := range src.Cues {
+		s, o := src.Cues[i], out.Cues[i]
+		if s.Start != o.Start || s.End != o.End {
+			return fmt.Errorf("%w: cue %d is %s --> %s, expected %s --> %s", ErrTimingChanged, i+1,
+				FormatTimestamp(o.Start), FormatTimestamp(o.End), FormatTimestamp(s.Start), FormatTimestamp(s.End))
+		}
+		if o.IsEmpty() && !s.IsEmpty() {
+			return fmt.Errorf("%w: cue %d", ErrEmptyCue, i+1)
+		}
+	}
+
+	return nil
+}
+
+// UntranslatedRatio returns the share of source cues with text whose
+// translation is identical to the original, ignoring case, whitespace and
+// formatting tags. Cues without letters (music notes, numbers) are skipped.
+func UntranslatedRatio(src, out *Subtitle) float64 {
+	total, same := 0, 0
+	for i := range src.Cues {
+		if i >= len(out.Cues) {
+			break
+		}
+		original := normalizeText(src.Cues[i].Text())
+		if !hasLetter(original) {
+			continue
+		}
+		total++
+		if normalizeText(out.Cues[i].Text()) == original {
+			same++
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(same) / float64(total)
+}
+
+var tagPattern = regexp.MustCompile(`<[^>]*>|\{[^}]*\}`)
+
+func normalizeText(s string) string {
+	s = tagPattern.ReplaceAllString(s, "")
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+func hasLetter(s string) bool {
+	for _, r := range s {
+		if unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
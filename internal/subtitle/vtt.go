@@ -0,0 +1,231 @@
+package subtitle
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ErrNotVTT marks input that does not start with the WEBVTT signature.
+var ErrNotVTT = errors.New("not a WebVTT file")
+
+// vttScript is everything of a parsed WebVTT file except the cue text: the
+// header, NOTE, STYLE and REGION blocks, and each cue's identifier, settings
+// and voice tag. Writing a Subtitle that carries it reproduces the file with
+// only the cue text replaced.
+type vttScript struct {
+	header []string // "WEBVTT" line and header text
+	blocks []vttBlock
+	cues   []vttCue // One per cue
+}
+
+// vttBlock is a cue, or a block that is kept verbatim.
+type vttBlock struct {
+	raw []string // NOTE, STYLE or REGION block; nil for cues
+	cue int      // Index into cues
+}
+
+type vttCue struct {
+	id       string
+	settings string // e.g. "position:10% align:start"
+	voice    string // Leading <v Name> tag, kept out of the translated text
+	voiceEnd bool   // Whether the voice span was closed with </v>
+}
+
+var (
+	vttTimingPattern = regexp.MustCompile(`^\s*((?:\d+:)?\d{1,2}:\d{1,2}\.\d{1,3})\s+-->\s+((?:\d+:)?\d{1,2}:\d{1,2}\.\d{1,3})(.*)$`)
+	vttVoicePattern  = regexp.MustCompile(`^<v[ .][^>]*>`)
+	// Tags SRT players do not know: classes, voices, languages, ruby and
+	// karaoke timestamps
+	vttOnlyTagPattern = regexp.MustCompile(`</?(?:c|v|lang|ruby|rt)(?:[ .][^>]*)?>|<\d[^>]*>`)
+)
+
+// IsVTT reports whether path is a WebVTT file by its extension.
+func IsVTT(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".vtt")
+}
+
+// ParseVTT parses WebVTT content. A cue's leading voice tag (<v Name>) is
+// kept aside so the speaker name is not translated; other tags stay in the
+// text. NOTE, STYLE and REGION blocks are kept and written back unchanged.
+func ParseVTT(data []byte) (*Subtitle, error) {
+	sub := &Subtitle{}
+
+	if bytes.HasPrefix(data, utf8BOM) {
+		sub.BOM = true
+		data = data[len(utf8BOM):]
+	}
+
+	text := string(data)
+	if strings.Contains(text, "\r\n") {
+		sub.CRLF = true
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+	}
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	blocks := splitBlocks(text)
+	if len(blocks) == 0 || !isVTTSignature(blocks[0][0]) {
+		return nil, ErrNotVTT
+	}
+
+	script := &vttScript{header: blocks[0]}
+	for _, block := range blocks[1:] {
+		if isVTTRawBlock(block[0]) {
+			script.blocks = append(script.blocks, vttBlock{raw: block})
+			continue
+		}
+
+		var meta vttCue
+		if !strings.Contains(block[0], "-->") {
+			meta.id = block[0]
+			block = block[1:]
+			if len(block) == 0 {
+				return nil, fmt.Errorf("cue %q: missing timing", meta.id)
+			}
+		}
+
+		m := vttTimingPattern.FindStringSubmatch(block[0])
+		if m == nil {
+			return nil, fmt.Errorf("invalid timing line %q", strings.TrimSpace(block[0]))
+		}
+		start, end := parseVTTTimestamp(m[1]), parseVTTTimestamp(m[2])
+		meta.settings = strings.TrimSpace(m[3])
+
+		body := strings.Join(block[1:], "\n")
+		if voice := vttVoicePattern.FindString(body); voice != "" {
+			meta.voice = voice
+			body = body[len(voice):]
+			if strings.HasSuffix(body, "</v>") {
+				meta.voiceEnd = true
+				body = strings.TrimSuffix(body, "</v>")
+			}
+		}
+
+		var lines []string
+		if body != "" {
+			lines = strings.Split(body, "\n")
+		}
+		script.blocks = append(script.blocks, vttBlock{cue: len(script.cues)})
+		script.cues = append(script.cues, meta)
+		sub.Cues = append(sub.Cues, Cue{Index: len(sub.Cues) + 1, Start: start, End: end, Lines: lines})
+	}
+
+	sub.vtt = script
+	return sub, nil
+}
+
+// splitBlocks splits text into blocks of non-blank lines.
+func splitBlocks(text string) [][]string {
+	var blocks [][]string
+	var current []string
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			if len(current) > 0 {
+				blocks = append(blocks, current)
+				current = nil
+			}
+			continue
+		}
+		current = append(current, strings.TrimRight(line, " \t"))
+	}
+	if len(current) > 0 {
+		blocks = append(blocks, current)
+	}
+	return blocks
+}
+
+func isVTTSignature(line string) bool {
+	return line == "WEBVTT" || strings.HasPrefix(line, "WEBVTT ") || strings.HasPrefix(line, "WEBVTT\t")
+}
+
+func isVTTRawBlock(line string) bool {
+	for _, kw := range []string{"NOTE", "STYLE", "REGION"} {
+		if line == kw || strings.HasPrefix(line, kw+" ") || strings.HasPrefix(line, kw+"\t") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseVTTTimestamp parses a timestamp matched by vttTimingPattern; the
+// hours are optional.
+func parseVTTTimestamp(s string) time.Duration {
+	parts := strings.Split(s, ":")
+	if len(parts) == 2 {
+		parts = append([]string{"0"}, parts...)
+	}
+	sec, frac, _ := strings.Cut(parts[2], ".")
+	return timestamp([]string{parts[0], parts[1], sec, frac})
+}
+
+// FormatVTTTimestamp formats d as a WebVTT timestamp (HH:MM:SS.mmm).
+func FormatVTTTimestamp(d time.Duration) string {
+	return strings.Replace(FormatTimestamp(d), ",", ".", 1)
+}
+
+// WriteVTT writes sub as WebVTT. A subtitle parsed from WebVTT is written back
+// with its header, blocks and cue settings; any other subtitle gets a plain
+// WEBVTT header. ASS override blocks of converted sources are dropped.
+func WriteVTT(w io.Writer, sub *Subtitle) error {
+	var buf bytes.Buffer
+	if sub.BOM {
+		buf.Write(utf8BOM)
+	}
+
+	s := sub.vtt
+	if s == nil || len(s.cues) != len(sub.Cues) {
+		s = &vttScript{header: []string{"WEBVTT"}, cues: make([]vttCue, len(sub.Cues))}
+		for i := range sub.Cues {
+			s.blocks = append(s.blocks, vttBlock{cue: i})
+		}
+	}
+
+	for _, l := range s.header {
+		buf.WriteString(l + "\n")
+	}
+	for _, b := range s.blocks {
+		buf.WriteString("\n")
+		if b.raw != nil {
+			for _, l := range b.raw {
+				buf.WriteString(l + "\n")
+			}
+			continue
+		}
+
+		meta, c := s.cues[b.cue], sub.Cues[b.cue]
+		if meta.id != "" {
+			buf.WriteString(meta.id + "\n")
+		}
+		buf.WriteString(FormatVTTTimestamp(c.Start) + " --> " + FormatVTTTimestamp(c.End))
+		if meta.settings != "" {
+			buf.WriteString(" " + meta.settings)
+		}
+		buf.WriteString("\n")
+
+		text := c.Text()
+		if sub.ass != nil {
+			text = assBlockPattern.ReplaceAllString(text, "")
+		}
+		if meta.voice != "" {
+			text = meta.voice + text
+			if meta.voiceEnd {
+				text += "</v>"
+			}
+		}
+		if text != "" {
+			buf.WriteString(text + "\n")
+		}
+	}
+
+	out := buf.Bytes()
+	if sub.CRLF {
+		out = bytes.ReplaceAll(out, []byte("\n"), []byte("\r\n"))
+	}
+	_, err := w.Write(out)
+	return err
+}
@@ -0,0 +1,209 @@
+package subtitle
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseVTT(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []Cue
+	}{
+		{
+			name: "basic",
+			in:   "WEBVTT\n\n00:00:01.000 --> 00:00:02.500\nHello.\n\n00:00:03.000 --> 00:00:04.000\nTwo\nlines\n",
+			want: []Cue{
+				{Index: 1, Start: ms(1000), End: ms(2500), Lines: []string{"Hello."}},
+				{Index: 2, Start: ms(3000), End: ms(4000), Lines: []string{"Two", "lines"}},
+			},
+		},
+		{
+			name: "hours optional, identifiers and settings",
+			in:   "WEBVTT - Title\n\nintro\n00:01.000 --> 00:02.000 position:10% align:start\nHi\n",
+			want: []Cue{{Index: 1, Start: ms(1000), End: ms(2000), Lines: []string{"Hi"}}},
+		},
+		{
+			name: "voice kept out of the text",
+			in:   "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\n<v Roger Bingham>We are in New York City</v>\n",
+			want: []Cue{{Index: 1, Start: ms(1000), End: ms(2000), Lines: []string{"We are in New York City"}}},
+		},
+		{
+			name: "classes stay in the text",
+			in:   "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\n<c.loud>Stop!</c>\n",
+			want: []Cue{{Index: 1, Start: ms(1000), End: ms(2000), Lines: []string{"<c.loud>Stop!</c>"}}},
+		},
+		{
+			name: "NOTE, STYLE and REGION are not cues",
+			in: "WEBVTT\n\nNOTE made by hand\n\nSTYLE\n::cue { color: yellow }\n\n" +
+				"REGION\nid:r1 width:40%\n\n00:00:01.000 --> 00:00:02.000 region:r1\nHi\n",
+			want: []Cue{{Index: 1, Start: ms(1000), End: ms(2000), Lines: []string{"Hi"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, err := ParseVTT([]byte(tt.in))
+			if err != nil {
+				t.Fatalf("ParseVTT: %v", err)
+			}
+			assertCues(t, sub.Cues, tt.want)
+		})
+	}
+}
+
+func TestParseVTTErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want error
+	}{
+		{"no signature", "1\n00:00:01,000 --> 00:00:02,000\nHi\n", ErrNotVTT},
+		{"empty", "", ErrNotVTT},
+		{"identifier without timing", "WEBVTT\n\nintro\n", nil},
+		{"invalid timing", "WEBVTT\n\n00:00:01 --> 00:00:02\nHi\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseVTT([]byte(tt.in))
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVTTRoundTrip(t *testing.T) {
+	file := "WEBVTT - Episode 1\nKind: captions\n\n" +
+		"NOTE made by hand\n\n" +
+		"STYLE\n::cue(.loud) { color: red; }\n\n" +
+		"intro\n00:00:01.000 --> 00:00:02.000 position:10% align:start\n<v Anna>Hello.</v>\n\n" +
+		"00:00:03.000 --> 00:00:04.000\n<c.loud>Stop!</c>\nNow.\n\n" +
+		"00:00:05.000 --> 00:00:06.000\n<v.first Bob>Unclosed voice\n"
+
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"LF", file},
+		{"BOM and CRLF", "\ufeff" + strings.ReplaceAll(file, "\n", "\r\n")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, err := ParseVTT([]byte(tt.in))
+			if err != nil {
+				t.Fatalf("ParseVTT: %v", err)
+			}
+			var b bytes.Buffer
+			if err := WriteVTT(&b, sub); err != nil {
+				t.Fatalf("WriteVTT: %v", err)
+			}
+			if b.String() != tt.in {
+				t.Errorf("round trip =\n%q\nwant\n%q", b.String(), tt.in)
+			}
+		})
+	}
+}
+
+func TestWriteVTTTranslated(t *testing.T) {
+	sub, err := ParseVTT([]byte("WEBVTT\n\nintro\n00:00:01.000 --> 00:00:02.000 align:start\n<v Anna>Hello.</v>\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := sub.Clone()
+	out.Cues[0].Lines = []string{"Hallo."}
+
+	var b bytes.Buffer
+	if err := WriteVTT(&b, out); err != nil {
+		t.Fatal(err)
+	}
+	want := "WEBVTT\n\nintro\n00:00:01.000 --> 00:00:02.000 align:start\n<v Anna>Hallo.</v>\n"
+	if b.String() != want {
+		t.Errorf("WriteVTT =\n%q\nwant\n%q", b.String(), want)
+	}
+}
+
+func TestVTTConversions(t *testing.T) {
+	tests := []struct {
+		name  string
+		parse func([]byte) (*Subtitle, error)
+		in    string
+		write func(*bytes.Buffer, *Subtitle) error
+		want  string
+	}{
+		{
+			name:  "SRT to WebVTT",
+			parse: ParseSRT,
+			in:    "1\n00:00:01,000 --> 00:00:02,000\n<i>Hi</i>\n",
+			write: func(b *bytes.Buffer, s *Subtitle) error { return WriteVTT(b, s) },
+			want:  "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\n<i>Hi</i>\n",
+		},
+		{
+			name:  "ASS to WebVTT drops override blocks",
+			parse: ParseASS,
+			in:    assEventsHeader + "Dialogue: 0,0:00:01.00,0:00:02.00,Default,,0,0,0,,{\\an8}Big {\\fs30}word\n",
+			write: func(b *bytes.Buffer, s *Subtitle) error { return WriteVTT(b, s) },
+			want:  "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\nBig word\n",
+		},
+		{
+			name:  "WebVTT to SRT drops WebVTT tags",
+			parse: ParseVTT,
+			in:    "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\n<v Anna><c.loud>Stop</c> <i>now</i></v>\n",
+			write: func(b *bytes.Buffer, s *Subtitle) error { return WriteSRT(b, s) },
+			want:  "1\n00:00:01,000 --> 00:00:02,000\nStop <i>now</i>\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, err := tt.parse([]byte(tt.in))
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			var b bytes.Buffer
+			if err := tt.write(&b, sub); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+			if b.String() != tt.want {
+				t.Errorf("got\n%q\nwant\n%q", b.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestBilingualVTT(t *testing.T) {
+	src, err := ParseVTT([]byte("WEBVTT\n\nNOTE kept\n\n00:00:01.000 --> 00:00:02.000\nHello.\n\n00:00:03.000 --> 00:00:04.000\n♪\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := src.Clone()
+	out.Cues[0].Lines = []string{"你好。"}
+
+	bi, err := Bilingual(src, out, BilingualOptions{
+		VTT:         true,
+		SourceStyle: BilingualStyle{Color: "#AAAAAA", Size: 14, Italic: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := WriteVTT(&b, bi); err != nil {
+		t.Fatal(err)
+	}
+	want := "WEBVTT\n\n" +
+		"STYLE\n::cue(.source) {\n  color: #AAAAAA;\n  font-size: 14px;\n  font-style: italic;\n}\n\n" +
+		"NOTE kept\n\n" +
+		"00:00:01.000 --> 00:00:02.000\n<c.translation>你好。</c>\n<c.source>Hello.</c>\n\n" +
+		"00:00:03.000 --> 00:00:04.000\n<c.translation>♪</c>\n"
+	if b.String() != want {
+		t.Errorf("bilingual WebVTT =\n%s\nwant\n%s", b.String(), want)
+	}
+	if strings.Contains(b.String(), "<font") {
+		t.Error("bilingual WebVTT uses <font> tags")
+	}
+}
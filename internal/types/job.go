@@ -2,7 +2,9 @@ package types
 
 import (
 	"errors"
+	"fmt"
 	"path/filepath"
+	"regexp"
 	"strings"
 )
 
@@ -12,22 +14,172 @@ type JobMessage struct {
 	SubtitlePath string `json:"subtitle_path"`
 	MediaTitle   string `json:"media_title"`
 	MediaType    string `json:"media_type"`
+
+	// SubtitleStream selects the embedded track extracted from VideoPath when
+	// SubtitlePath is empty: an absolute stream index ("3") or a language tag
+	// ("jpn"). Empty picks the best English text track.
+	SubtitleStream string `json:"subtitle_stream,omitempty"`
+
+	// Targets lists the languages to produce, one output file each. Empty
+	// uses the worker's configured targets.
+	Targets []Target `json:"targets,omitempty"`
+
+	// Optional per-job overrides of the worker configuration
+	TargetLanguage string `json:"target_language,omitempty"` // Single target, with output_suffix; shorthand for targets
+	OutputSuffix   string `json:"output_suffix,omitempty"`
+	Provider       string `json:"provider,omitempty"`    // Provider tried first, e.g. "openrouter"
+	Model          string `json:"model,omitempty"`       // Model used by Provider instead of its configured one
+	Instruction    string `json:"instruction,omitempty"` // Replaces the providers' configured instruction
+	Priority       string `json:"priority,omitempty"`    // "high", "normal" (default) or "low"
+}
+
+// Job priorities. Higher priority jobs get a busy provider's next free slot.
+const (
+	PriorityHigh   = "high"
+	PriorityNormal = "normal"
+	PriorityLow    = "low"
+)
+
+// Priorities lists the job priorities, highest first.
+var Priorities = []string{PriorityHigh, PriorityNormal, PriorityLow}
+
+// IsPriority reports whether p is a known job priority.
+func IsPriority(p string) bool {
+	for _, v := range Priorities {
+		if v == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Providers are the translator provider names a job may ask for.
+var Providers = []string{"gemini", "openrouter", "local_llm"}
+
+// IsProvider reports whether name is a known translator provider.
+func IsProvider(name string) bool {
+	for _, p := range Providers {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
+
+// safeID matches the IDs the worker uses in file system paths.
+var safeID = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// IsSafeID reports whether id only has ASCII letters, digits, '-' and '_',
+// so it can name a directory without escaping its parent.
+func IsSafeID(id string) bool {
+	return safeID.MatchString(id)
+}
+
+// Target is one language a job is translated into.
+type Target struct {
+	Code     string `json:"code"`             // Short language code, e.g. "chs"; keys the callback payload
+	Language string `json:"language"`         // Language name passed to the model, e.g. "Simplified Chinese"
+	Suffix   string `json:"suffix,omitempty"` // Output file suffix (default: Code)
+	Font     string `json:"font,omitempty"`   // Font of translated ASS dialogue (default: translator.ass.font)
+}
+
+// OutputSuffix returns the suffix of the translated file for t.
+func (t Target) OutputSuffix() string {
+	if t.Suffix != "" {
+		return t.Suffix
+	}
+	return t.Code
+}
+
+// ValidateTargets checks that every target is complete and that no two
+// targets share a code or an output file.
+func ValidateTargets(targets []Target) error {
+	codes := make(map[string]bool, len(targets))
+	suffixes := make(map[string]bool, len(targets))
+	for i, t := range targets {
+		code := strings.ToLower(strings.TrimSpace(t.Code))
+		suffix := strings.ToLower(strings.TrimPrefix(t.OutputSuffix(), "."))
+		switch {
+		case code == "":
+			return fmt.Errorf("targets[%d]: code is required", i)
+		case !IsSafeID(t.Code):
+			return fmt.Errorf("targets[%d]: code %q may only contain letters, digits, '-' and '_'", i, t.Code)
+		case strings.TrimSpace(t.Language) == "":
+			return fmt.Errorf("targets[%d]: language is required", i)
+		case codes[code]:
+			return fmt.Errorf("targets[%d]: duplicate code %q", i, t.Code)
+		case suffixes[suffix]:
+			return fmt.Errorf("targets[%d]: duplicate output suffix %q", i, t.OutputSuffix())
+		}
+		codes[code] = true
+		suffixes[suffix] = true
+	}
+	return nil
 }
 
 func (m JobMessage) Validate() error {
 	if strings.TrimSpace(m.JobID) == "" {
 		return errors.New("job_id is required")
 	}
+	if !IsSafeID(m.JobID) {
+		return fmt.Errorf("job_id %q may only contain letters, digits, '-' and '_'", m.JobID)
+	}
 	if strings.TrimSpace(m.VideoPath) == "" {
 		return errors.New("video_path is required")
 	}
-	if strings.TrimSpace(m.SubtitlePath) == "" {
-		return errors.New("subtitle_path is required")
+	if strings.TrimSpace(m.SubtitlePath) != "" && m.SubtitleStream != "" {
+		return errors.New("subtitle_stream cannot be combined with subtitle_path")
+	}
+	// The shorthand target's code is its suffix, so it is checked like any other
+	if err := ValidateTargets(m.JobTargets(nil)); err != nil {
+		return err
+	}
+
+	switch {
+	case (m.TargetLanguage == "") != (m.OutputSuffix == ""):
+		return errors.New("target_language and output_suffix must be set together")
+	case m.TargetLanguage != "" && len(m.Targets) > 0:
+		return errors.New("target_language cannot be combined with targets")
+	case m.Provider != "" && !IsProvider(m.Provider):
+		return fmt.Errorf("unknown provider %q", m.Provider)
+	case m.Model != "" && m.Provider == "":
+		return errors.New("model requires provider")
+	}
+
+	if m.Priority != "" && !IsPriority(m.Priority) {
+		return fmt.Errorf("priority must be %q, %q or %q", PriorityHigh, PriorityNormal, PriorityLow)
 	}
 
 	return nil
 }
 
+// JobTargets returns the targets the job asks for, or defaults if it names none.
+func (m JobMessage) JobTargets(defaults []Target) []Target {
+	if len(m.Targets) > 0 {
+		return m.Targets
+	}
+	if m.TargetLanguage != "" {
+		return []Target{{Code: m.OutputSuffix, Language: m.TargetLanguage}}
+	}
+	return defaults
+}
+
+// ModelFor returns the job's model if it was requested for provider, otherwise def.
+func (m JobMessage) ModelFor(provider, def string) string {
+	if m.Model != "" && m.Provider == provider {
+		return m.Model
+	}
+	return def
+}
+
+// InstructionOr returns the job's instruction, or def if it has none.
+func (m JobMessage) InstructionOr(def string) string {
+	if m.Instruction != "" {
+		return m.Instruction
+	}
+	return def
+}
+
 func (m JobMessage) OutputPath(suffix string) string {
 	if suffix == "" {
 		return m.SubtitlePath
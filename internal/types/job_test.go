@@ -0,0 +1,41 @@
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateIDs(t *testing.T) {
+	valid := JobMessage{JobID: "3f2b-9c_1", VideoPath: "/media/show.mkv", SubtitlePath: "/media/show.en.srt"}
+	tests := []struct {
+		name    string
+		edit    func(*JobMessage)
+		wantErr string // Empty when the job is valid
+	}{
+		{"valid", func(*JobMessage) {}, ""},
+		{"valid target code", func(m *JobMessage) { m.Targets = []Target{{Code: "zh-Hans", Language: "Chinese"}} }, ""},
+		{"valid shorthand", func(m *JobMessage) { m.TargetLanguage, m.OutputSuffix = "Chinese", "chs" }, ""},
+		{"job_id parent directory", func(m *JobMessage) { m.JobID = ".." }, "job_id"},
+		{"job_id with separator", func(m *JobMessage) { m.JobID = "../../etc" }, "job_id"},
+		{"job_id with backslash", func(m *JobMessage) { m.JobID = `a\b` }, "job_id"},
+		{"job_id with space", func(m *JobMessage) { m.JobID = "a b" }, "job_id"},
+		{"job_id not ASCII", func(m *JobMessage) { m.JobID = "jöb" }, "job_id"},
+		{"code with separator", func(m *JobMessage) { m.Targets = []Target{{Code: "../chs", Language: "Chinese"}} }, "code"},
+		{"code parent directory", func(m *JobMessage) { m.Targets = []Target{{Code: "..", Language: "Chinese"}} }, "code"},
+		{"shorthand suffix with separator", func(m *JobMessage) { m.TargetLanguage, m.OutputSuffix = "Chinese", "x/../../chs" }, "code"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := valid
+			tt.edit(&m)
+			err := m.Validate()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Validate: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("err = %v, want a %s error", err, tt.wantErr)
+			}
+		})
+	}
+}